@@ -4,19 +4,18 @@ go 1.25.5
 
 require (
 	github.com/Votline/Gurlf v1.2.1-0.20260331065503-d049f1ef841e
-	github.com/google/uuid v1.6.0
+	github.com/gorilla/websocket v1.5.3
 	github.com/jhump/protoreflect v1.18.0
 	go.uber.org/zap v1.27.1
+	golang.org/x/net v0.47.0
 	google.golang.org/grpc v1.78.0
 )
 
 require (
 	github.com/golang/protobuf v1.5.4 // indirect
-	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/jhump/protoreflect/v2 v2.0.0-beta.1 // indirect
 	github.com/petermattis/goid v0.0.0-20260113132338-7c7de50cc741 // indirect
 	go.uber.org/multierr v1.10.0 // indirect
-	golang.org/x/net v0.47.0 // indirect
 	golang.org/x/sync v0.18.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
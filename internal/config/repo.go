@@ -27,6 +27,9 @@ const (
 	// DataFromEnvironment for instruction with data from environment.
 	DataFromEnvironment int = -6
 
+	// DataFromRow for instruction with data from row of 'DataFile'.
+	DataFromRow int = -7
+
 	// FlagUseFileCookies for use cookies from current config.
 	FlagUseFileCookies uint32 = 1
 )
@@ -48,6 +51,10 @@ type Dependency struct {
 
 	// InsTp is a type of dependency. Like 'RESPONSE' or 'COOKIES'
 	InsTp string
+
+	// Inner is a config name or id inside imported file.
+	// Like 'login' in 'id=auth.login'. Empty for current file.
+	Inner string
 }
 
 // Config is a interface for config.
@@ -86,12 +93,83 @@ type Config interface {
 	// SetTimeout sets timeout field.
 	SetTimeout([]byte)
 
+	// GetRetry returns retry field.
+	GetRetry() []byte
+
+	// SetRetry sets retry field.
+	SetRetry([]byte)
+
+	// GetUntil returns until field.
+	GetUntil() []byte
+
+	// SetUntil sets until field.
+	SetUntil([]byte)
+
+	// GetInterval returns interval field.
+	GetInterval() []byte
+
+	// SetInterval sets interval field.
+	SetInterval([]byte)
+
+	// GetMaxWait returns max wait field.
+	GetMaxWait() []byte
+
+	// SetMaxWait sets max wait field.
+	SetMaxWait([]byte)
+
+	// GetDataFile returns data file field.
+	GetDataFile() []byte
+
+	// SetDataFile sets data file field.
+	SetDataFile([]byte)
+
+	// GetForEach returns for each field.
+	// Config is executed once per element of array.
+	GetForEach() []byte
+
+	// SetForEach sets for each field.
+	SetForEach([]byte)
+
 	// GetExpect returns expect field.
 	GetExpect() []byte
 
 	// SetExpect sets expect field.
 	SetExpect([]byte)
 
+	// GetAssert returns assert field.
+	GetAssert() []byte
+
+	// SetAssert sets assert field.
+	SetAssert([]byte)
+
+	// GetCapture returns capture field.
+	GetCapture() []byte
+
+	// SetCapture sets capture field.
+	SetCapture([]byte)
+
+	// GetIf returns if field.
+	// Config is skipped if condition is false.
+	GetIf() []byte
+
+	// SetIf sets if field.
+	SetIf([]byte)
+
+	// GetAlways returns always field.
+	// Config with true value is executed even after crash or jump.
+	GetAlways() []byte
+
+	// SetAlways sets always field.
+	SetAlways([]byte)
+
+	// GetGroup returns group field.
+	// Configs of one group are executed in order in parallel mode.
+	GetGroup() []byte
+
+	// GetAfter returns after field.
+	// It is a list of ids and groups executed before config in parallel mode.
+	GetAfter() []byte
+
 	// GetCerts returns certs field.
 	GetCerts() []byte
 
@@ -116,6 +194,13 @@ type Config interface {
 	// GetRaw returns raw data by key.
 	GetRaw(string) []byte
 
+	// SetOwn saves value of field before defaults.
+	SetOwn(string, []byte)
+
+	// StripDefaults restores values of fields before defaults.
+	// Config is written to file without defaults.
+	StripDefaults()
+
 	// UnwrapExec returns config for execution.
 	UnwrapExec() Config
 
@@ -222,10 +307,30 @@ func Alloc(cfg Config) Config {
 		cp.Body = cloneBytes(v.Body)
 		cp.Headers = cloneBytes(v.Headers)
 		cp.Timeout = cloneBytes(v.Timeout)
+		cp.Retry = cloneBytes(v.Retry)
+		cp.Until = cloneBytes(v.Until)
+		cp.Interval = cloneBytes(v.Interval)
+		cp.MaxWait = cloneBytes(v.MaxWait)
+		cp.DataFile = cloneBytes(v.DataFile)
+		cp.ForEach = cloneBytes(v.ForEach)
 		cp.CookieIn = cloneBytes(v.CookieIn)
 		cp.CookieOut = cloneBytes(v.CookieOut)
+		cp.KeepAlive = cloneBytes(v.KeepAlive)
+		cp.ClientCert = cloneBytes(v.ClientCert)
+		cp.ClientKey = cloneBytes(v.ClientKey)
+		cp.ClientKeyPass = cloneBytes(v.ClientKeyPass)
+		cp.ServerName = cloneBytes(v.ServerName)
+		cp.TLSMinVersion = cloneBytes(v.TLSMinVersion)
+		cp.Proxy = cloneBytes(v.Proxy)
+		cp.NoProxy = cloneBytes(v.NoProxy)
 		cp.Wait = cloneBytes(v.Wait)
 		cp.Expect = cloneBytes(v.Expect)
+		cp.Assert = cloneBytes(v.Assert)
+		cp.If = cloneBytes(v.If)
+		cp.Always = cloneBytes(v.Always)
+		cp.Capture = cloneBytes(v.Capture)
+		cp.Group = cloneBytes(v.Group)
+		cp.After = cloneBytes(v.After)
 		cp.Certs = cloneBytes(v.Certs)
 		cp.Vars = cloneBytes(v.Vars)
 		cp.Envs = cloneBytes(v.Envs)
@@ -237,11 +342,30 @@ func Alloc(cfg Config) Config {
 		cp.Endpoint = cloneBytes(v.Endpoint)
 		cp.Data = cloneBytes(v.Data)
 		cp.Timeout = cloneBytes(v.Timeout)
+		cp.Retry = cloneBytes(v.Retry)
+		cp.Until = cloneBytes(v.Until)
+		cp.Interval = cloneBytes(v.Interval)
+		cp.MaxWait = cloneBytes(v.MaxWait)
+		cp.DataFile = cloneBytes(v.DataFile)
+		cp.ForEach = cloneBytes(v.ForEach)
 		cp.ProtoPath = cloneBytes(v.ProtoPath)
 		cp.ImportPaths = cloneBytes(v.ImportPaths)
 		cp.DialOpts = cloneBytes(v.DialOpts)
+		cp.ClientCert = cloneBytes(v.ClientCert)
+		cp.ClientKey = cloneBytes(v.ClientKey)
+		cp.ClientKeyPass = cloneBytes(v.ClientKeyPass)
+		cp.ServerName = cloneBytes(v.ServerName)
+		cp.TLSMinVersion = cloneBytes(v.TLSMinVersion)
+		cp.Proxy = cloneBytes(v.Proxy)
+		cp.NoProxy = cloneBytes(v.NoProxy)
 		cp.Wait = cloneBytes(v.Wait)
 		cp.Expect = cloneBytes(v.Expect)
+		cp.Assert = cloneBytes(v.Assert)
+		cp.If = cloneBytes(v.If)
+		cp.Always = cloneBytes(v.Always)
+		cp.Capture = cloneBytes(v.Capture)
+		cp.Group = cloneBytes(v.Group)
+		cp.After = cloneBytes(v.After)
 		cp.Certs = cloneBytes(v.Certs)
 		cp.Vars = cloneBytes(v.Vars)
 		cp.Envs = cloneBytes(v.Envs)
@@ -251,7 +375,19 @@ func Alloc(cfg Config) Config {
 		*cp = *v
 		cp.Wait = cloneBytes(v.Wait)
 		cp.Timeout = cloneBytes(v.Timeout)
+		cp.Retry = cloneBytes(v.Retry)
+		cp.Until = cloneBytes(v.Until)
+		cp.Interval = cloneBytes(v.Interval)
+		cp.MaxWait = cloneBytes(v.MaxWait)
+		cp.DataFile = cloneBytes(v.DataFile)
+		cp.ForEach = cloneBytes(v.ForEach)
 		cp.Expect = cloneBytes(v.Expect)
+		cp.Assert = cloneBytes(v.Assert)
+		cp.If = cloneBytes(v.If)
+		cp.Always = cloneBytes(v.Always)
+		cp.Capture = cloneBytes(v.Capture)
+		cp.Group = cloneBytes(v.Group)
+		cp.After = cloneBytes(v.After)
 		cp.Certs = cloneBytes(v.Certs)
 		cp.Vars = cloneBytes(v.Vars)
 		cp.Envs = cloneBytes(v.Envs)
@@ -261,7 +397,19 @@ func Alloc(cfg Config) Config {
 		*cp = *v
 		cp.Wait = cloneBytes(v.Wait)
 		cp.Timeout = cloneBytes(v.Timeout)
+		cp.Retry = cloneBytes(v.Retry)
+		cp.Until = cloneBytes(v.Until)
+		cp.Interval = cloneBytes(v.Interval)
+		cp.MaxWait = cloneBytes(v.MaxWait)
+		cp.DataFile = cloneBytes(v.DataFile)
+		cp.ForEach = cloneBytes(v.ForEach)
 		cp.Expect = cloneBytes(v.Expect)
+		cp.Assert = cloneBytes(v.Assert)
+		cp.If = cloneBytes(v.If)
+		cp.Always = cloneBytes(v.Always)
+		cp.Capture = cloneBytes(v.Capture)
+		cp.Group = cloneBytes(v.Group)
+		cp.After = cloneBytes(v.After)
 		cp.Certs = cloneBytes(v.Certs)
 		cp.TargetPath = v.TargetPath
 		cp.Vars = cloneBytes(v.Vars)
@@ -278,9 +426,21 @@ type BaseConfig struct {
 	Len       int
 	Name      string `gurlf:"config_name"`
 	Type      string `gurlf:"Type"`
+	Group     []byte `gurlf:"Group,omitempty"`
+	After     []byte `gurlf:"After,omitempty"`
+	If        []byte `gurlf:"If,omitempty"`
+	Always    []byte `gurlf:"Always,omitempty"`
 	Wait      []byte `gurlf:"Wait,omitempty"`
 	Timeout   []byte `gurlf:"Timeout,omitempty"`
+	Retry     []byte `gurlf:"Retry,omitempty"`
+	Until     []byte `gurlf:"Until,omitempty"`
+	Interval  []byte `gurlf:"Interval,omitempty"`
+	MaxWait   []byte `gurlf:"MaxWait,omitempty"`
+	DataFile  []byte `gurlf:"DataFile,omitempty"`
+	ForEach   []byte `gurlf:"ForEach,omitempty"`
 	Expect    []byte `gurlf:"Expect,omitempty"`
+	Assert    []byte `gurlf:"Assert,omitempty"`
+	Capture   []byte `gurlf:"Capture,omitempty"`
 	Certs     []byte `gurlf:"Certs,omitempty"`
 	Vars      []byte `gurlf:"SetVariables,omitempty"`
 	Envs      []byte `gurlf:"SetEnvironments,omitempty"`
@@ -288,6 +448,7 @@ type BaseConfig struct {
 	Deps      [6]Dependency
 	ExtraDeps []Dependency
 	DepsLen   uint8
+	Own       map[string][]byte
 	flag      uint32
 }
 
@@ -304,13 +465,36 @@ func (c *BaseConfig) Update(raw, cookie []byte)      {}
 func (c *BaseConfig) Apply(int, int, string, []byte) {}
 func (c *BaseConfig) GetDepsLen() uint8              { return c.DepsLen }
 func (c *BaseConfig) GetRaw(key string) []byte       { return nil }
+func (c *BaseConfig) StripDefaults()                 {}
 func (c *BaseConfig) GetName() string                { return c.Name }
 func (c *BaseConfig) GetWait() []byte                { return c.Wait }
 func (c *BaseConfig) SetWait(nWait []byte)           { c.Wait = nWait }
 func (c *BaseConfig) GetTimeout() []byte             { return c.Timeout }
 func (c *BaseConfig) SetTimeout(nTimeout []byte)     { c.Timeout = nTimeout }
+func (c *BaseConfig) GetRetry() []byte               { return c.Retry }
+func (c *BaseConfig) SetRetry(nRetry []byte)         { c.Retry = nRetry }
+func (c *BaseConfig) GetUntil() []byte               { return c.Until }
+func (c *BaseConfig) SetUntil(nUntil []byte)         { c.Until = nUntil }
+func (c *BaseConfig) GetInterval() []byte            { return c.Interval }
+func (c *BaseConfig) SetInterval(nInterval []byte)   { c.Interval = nInterval }
+func (c *BaseConfig) GetMaxWait() []byte             { return c.MaxWait }
+func (c *BaseConfig) SetMaxWait(nMaxWait []byte)     { c.MaxWait = nMaxWait }
+func (c *BaseConfig) GetDataFile() []byte            { return c.DataFile }
+func (c *BaseConfig) GetForEach() []byte             { return c.ForEach }
+func (c *BaseConfig) SetDataFile(nDataFile []byte)   { c.DataFile = nDataFile }
+func (c *BaseConfig) SetForEach(nForEach []byte)     { c.ForEach = nForEach }
 func (c *BaseConfig) GetExpect() []byte              { return c.Expect }
 func (c *BaseConfig) SetExpect(nExpect []byte)       { c.Expect = nExpect }
+func (c *BaseConfig) GetAssert() []byte              { return c.Assert }
+func (c *BaseConfig) SetAssert(nAssert []byte)       { c.Assert = nAssert }
+func (c *BaseConfig) GetCapture() []byte             { return c.Capture }
+func (c *BaseConfig) SetCapture(nCapture []byte)     { c.Capture = nCapture }
+func (c *BaseConfig) GetIf() []byte                  { return c.If }
+func (c *BaseConfig) GetAlways() []byte              { return c.Always }
+func (c *BaseConfig) SetIf(nIf []byte)               { c.If = nIf }
+func (c *BaseConfig) SetAlways(nAlways []byte)       { c.Always = nAlways }
+func (c *BaseConfig) GetGroup() []byte               { return c.Group }
+func (c *BaseConfig) GetAfter() []byte               { return c.After }
 func (c *BaseConfig) GetCerts() []byte               { return c.Certs }
 func (c *BaseConfig) SetCerts(nCerts []byte)         { c.Certs = nCerts }
 func (c *BaseConfig) GetVars() []byte                { return c.Vars }
@@ -327,13 +511,32 @@ func (c *BaseConfig) Clone() Config {
 	cp := *c
 	cp.Wait = cloneBytes(c.Wait)
 	cp.Timeout = cloneBytes(c.Timeout)
+	cp.Retry = cloneBytes(c.Retry)
+	cp.Until = cloneBytes(c.Until)
+	cp.Interval = cloneBytes(c.Interval)
+	cp.MaxWait = cloneBytes(c.MaxWait)
+	cp.DataFile = cloneBytes(c.DataFile)
+	cp.ForEach = cloneBytes(c.ForEach)
 	cp.Expect = cloneBytes(c.Expect)
+	cp.Assert = cloneBytes(c.Assert)
+	cp.If = cloneBytes(c.If)
+	cp.Always = cloneBytes(c.Always)
+	cp.Capture = cloneBytes(c.Capture)
+	cp.Group = cloneBytes(c.Group)
+	cp.After = cloneBytes(c.After)
 	cp.Certs = cloneBytes(c.Certs)
 	cp.Vars = cloneBytes(c.Vars)
 	cp.Envs = cloneBytes(c.Envs)
 	return &cp
 }
 
+func (c *BaseConfig) SetOwn(key string, val []byte) {
+	if c.Own == nil {
+		c.Own = make(map[string][]byte, 4)
+	}
+	c.Own[key] = val
+}
+
 func (c *BaseConfig) RangeDeps(fn func(d Dependency)) {
 	limit := c.DepsLen
 	limit = min(limit, 6)
@@ -377,6 +580,9 @@ type HTTPConfig struct {
 	BaseConfig
 	CookieIn  []byte `gurlf:"CookieIn,omitempty"`
 	CookieOut []byte `gurlf:"CookieOut,omitempty"`
+	KeepAlive []byte `gurlf:"KeepAlive,omitempty"`
+	TLSConfig
+	ProxyConfig
 }
 
 func GetHTTP() (*HTTPConfig, uintptr)    { return hBuf.Read(), hItab }
@@ -393,16 +599,38 @@ func (c *HTTPConfig) Clone() Config {
 	newCfg.Body = cloneBytes(c.Body)
 	newCfg.Headers = cloneBytes(c.Headers)
 	newCfg.Timeout = cloneBytes(c.Timeout)
+	newCfg.Retry = cloneBytes(c.Retry)
+	newCfg.Until = cloneBytes(c.Until)
+	newCfg.Interval = cloneBytes(c.Interval)
+	newCfg.MaxWait = cloneBytes(c.MaxWait)
+	newCfg.DataFile = cloneBytes(c.DataFile)
+	newCfg.ForEach = cloneBytes(c.ForEach)
 	newCfg.CookieIn = cloneBytes(c.CookieIn)
 	newCfg.CookieOut = cloneBytes(c.CookieOut)
+	newCfg.KeepAlive = cloneBytes(c.KeepAlive)
+	newCfg.ClientCert = cloneBytes(c.ClientCert)
+	newCfg.ClientKey = cloneBytes(c.ClientKey)
+	newCfg.ClientKeyPass = cloneBytes(c.ClientKeyPass)
+	newCfg.ServerName = cloneBytes(c.ServerName)
+	newCfg.TLSMinVersion = cloneBytes(c.TLSMinVersion)
+	newCfg.Proxy = cloneBytes(c.Proxy)
+	newCfg.NoProxy = cloneBytes(c.NoProxy)
 	newCfg.Wait = cloneBytes(c.Wait)
 	newCfg.Expect = cloneBytes(c.Expect)
+	newCfg.Assert = cloneBytes(c.Assert)
+	newCfg.If = cloneBytes(c.If)
+	newCfg.Always = cloneBytes(c.Always)
+	newCfg.Capture = cloneBytes(c.Capture)
+	newCfg.Group = cloneBytes(c.Group)
+	newCfg.After = cloneBytes(c.After)
 	newCfg.Certs = cloneBytes(c.Certs)
 	newCfg.Vars = cloneBytes(c.Vars)
 	newCfg.Envs = cloneBytes(c.Envs)
 	return newCfg
 }
 
+func (c *HTTPConfig) StripDefaults() { stripDefaults(c, c.Own) }
+
 func (c *HTTPConfig) Update(res, cks []byte) {
 	tmp := make([]byte, len(res))
 	copy(tmp, res)
@@ -422,14 +650,50 @@ func (c *HTTPConfig) GetRaw(key string) []byte {
 		return c.Headers
 	case "Timeout":
 		return c.Timeout
+	case "Retry":
+		return c.Retry
+	case "Until":
+		return c.Until
+	case "Interval":
+		return c.Interval
+	case "MaxWait":
+		return c.MaxWait
+	case "DataFile":
+		return c.DataFile
+	case "ForEach":
+		return c.ForEach
 	case "Cookie", "CookieIn":
 		return c.CookieIn
+	case "KeepAlive":
+		return c.KeepAlive
 	case "Wait":
 		return c.Wait
 	case "Expect":
 		return c.Expect
+	case "Assert":
+		return c.Assert
+	case "If":
+		return c.If
+	case "Always":
+		return c.Always
+	case "Capture":
+		return c.Capture
 	case "Certs":
 		return c.Certs
+	case "ClientCert":
+		return c.ClientCert
+	case "ClientKey":
+		return c.ClientKey
+	case "ClientKeyPass":
+		return c.ClientKeyPass
+	case "ServerName":
+		return c.ServerName
+	case "TLSMinVersion":
+		return c.TLSMinVersion
+	case "Proxy":
+		return c.Proxy
+	case "NoProxy":
+		return c.NoProxy
 	case "SetVariables":
 		return c.Vars
 	case "SetEnvironments":
@@ -450,14 +714,50 @@ func (c *HTTPConfig) Apply(start, end int, key string, val []byte) {
 		c.Headers = splice(c.Headers, val, start, end)
 	case "Timeout":
 		c.Timeout = splice(c.Timeout, val, start, end)
+	case "Retry":
+		c.Retry = splice(c.Retry, val, start, end)
+	case "Until":
+		c.Until = splice(c.Until, val, start, end)
+	case "Interval":
+		c.Interval = splice(c.Interval, val, start, end)
+	case "MaxWait":
+		c.MaxWait = splice(c.MaxWait, val, start, end)
+	case "DataFile":
+		c.DataFile = splice(c.DataFile, val, start, end)
+	case "ForEach":
+		c.ForEach = splice(c.ForEach, val, start, end)
 	case "Cookie", "CookieIn":
 		c.CookieIn = splice(c.CookieIn, val, start, end)
+	case "KeepAlive":
+		c.KeepAlive = splice(c.KeepAlive, val, start, end)
 	case "Wait":
 		c.Wait = splice(c.Wait, val, start, end)
 	case "Expect":
 		c.Expect = splice(c.Expect, val, start, end)
+	case "Assert":
+		c.Assert = splice(c.Assert, val, start, end)
+	case "If":
+		c.If = splice(c.If, val, start, end)
+	case "Always":
+		c.Always = splice(c.Always, val, start, end)
+	case "Capture":
+		c.Capture = splice(c.Capture, val, start, end)
 	case "Certs":
 		c.Certs = splice(c.Certs, val, start, end)
+	case "ClientCert":
+		c.ClientCert = splice(c.ClientCert, val, start, end)
+	case "ClientKey":
+		c.ClientKey = splice(c.ClientKey, val, start, end)
+	case "ClientKeyPass":
+		c.ClientKeyPass = splice(c.ClientKeyPass, val, start, end)
+	case "ServerName":
+		c.ServerName = splice(c.ServerName, val, start, end)
+	case "TLSMinVersion":
+		c.TLSMinVersion = splice(c.TLSMinVersion, val, start, end)
+	case "Proxy":
+		c.Proxy = splice(c.Proxy, val, start, end)
+	case "NoProxy":
+		c.NoProxy = splice(c.NoProxy, val, start, end)
 	case "SetVariables":
 		c.Vars = splice(c.Vars, val, start, end)
 	case "SetEnvironments":
@@ -465,6 +765,23 @@ func (c *HTTPConfig) Apply(start, end int, key string, val []byte) {
 	}
 }
 
+// TLSConfig is a TLS settings of HTTP, WebSocket and gRPC configs.
+// Certs field is a CA certificate, these fields are for mTLS and handshake.
+type TLSConfig struct {
+	ClientCert    []byte `gurlf:"ClientCert,omitempty"`
+	ClientKey     []byte `gurlf:"ClientKey,omitempty"`
+	ClientKeyPass []byte `gurlf:"ClientKeyPass,omitempty"`
+	ServerName    []byte `gurlf:"ServerName,omitempty"`
+	TLSMinVersion []byte `gurlf:"TLSMinVersion,omitempty"`
+}
+
+// ProxyConfig is a proxy settings of HTTP, WebSocket and gRPC configs.
+// Proxy overrides '--proxy' flag and proxy env vars, NoProxy overrides 'NO_PROXY'.
+type ProxyConfig struct {
+	Proxy   []byte `gurlf:"Proxy,omitempty"`
+	NoProxy []byte `gurlf:"NoProxy,omitempty"`
+}
+
 // GRPCConfig is a config for gRPC requests.
 type GRPCConfig struct {
 	Target      []byte `gurlf:"Target"`
@@ -475,6 +792,8 @@ type GRPCConfig struct {
 	ImportPaths []byte `gurlf:"ImportPaths,omitempty"`
 	DialOpts    []byte `gurlf:"DialOpts,omitempty"`
 	BaseConfig
+	TLSConfig
+	ProxyConfig
 }
 
 func GetGRPC() (*GRPCConfig, uintptr)    { return gBuf.Read(), gItab }
@@ -488,17 +807,38 @@ func (c *GRPCConfig) Clone() Config {
 	newCfg.Endpoint = cloneBytes(c.Endpoint)
 	newCfg.Data = cloneBytes(c.Data)
 	newCfg.Timeout = cloneBytes(c.Timeout)
+	newCfg.Retry = cloneBytes(c.Retry)
+	newCfg.Until = cloneBytes(c.Until)
+	newCfg.Interval = cloneBytes(c.Interval)
+	newCfg.MaxWait = cloneBytes(c.MaxWait)
+	newCfg.DataFile = cloneBytes(c.DataFile)
+	newCfg.ForEach = cloneBytes(c.ForEach)
 	newCfg.ProtoPath = cloneBytes(c.ProtoPath)
 	newCfg.ImportPaths = cloneBytes(c.ImportPaths)
 	newCfg.DialOpts = cloneBytes(c.DialOpts)
+	newCfg.ClientCert = cloneBytes(c.ClientCert)
+	newCfg.ClientKey = cloneBytes(c.ClientKey)
+	newCfg.ClientKeyPass = cloneBytes(c.ClientKeyPass)
+	newCfg.ServerName = cloneBytes(c.ServerName)
+	newCfg.TLSMinVersion = cloneBytes(c.TLSMinVersion)
+	newCfg.Proxy = cloneBytes(c.Proxy)
+	newCfg.NoProxy = cloneBytes(c.NoProxy)
 	newCfg.Wait = cloneBytes(c.Wait)
 	newCfg.Expect = cloneBytes(c.Expect)
+	newCfg.Assert = cloneBytes(c.Assert)
+	newCfg.If = cloneBytes(c.If)
+	newCfg.Always = cloneBytes(c.Always)
+	newCfg.Capture = cloneBytes(c.Capture)
+	newCfg.Group = cloneBytes(c.Group)
+	newCfg.After = cloneBytes(c.After)
 	newCfg.Certs = cloneBytes(c.Certs)
 	newCfg.Vars = cloneBytes(c.Vars)
 	newCfg.Envs = cloneBytes(c.Envs)
 	return newCfg
 }
 
+func (c *GRPCConfig) StripDefaults() { stripDefaults(c, c.Own) }
+
 func (c *GRPCConfig) Update(res, cks []byte) {
 	tmp := make([]byte, len(res))
 	copy(tmp, res)
@@ -515,6 +855,18 @@ func (c *GRPCConfig) GetRaw(key string) []byte {
 		return c.Data
 	case "Timeout":
 		return c.Timeout
+	case "Retry":
+		return c.Retry
+	case "Until":
+		return c.Until
+	case "Interval":
+		return c.Interval
+	case "MaxWait":
+		return c.MaxWait
+	case "DataFile":
+		return c.DataFile
+	case "ForEach":
+		return c.ForEach
 	case "Metadata":
 		return c.Metadata
 	case "ProtoPath":
@@ -527,8 +879,30 @@ func (c *GRPCConfig) GetRaw(key string) []byte {
 		return c.Wait
 	case "Expect":
 		return c.Expect
+	case "Assert":
+		return c.Assert
+	case "If":
+		return c.If
+	case "Always":
+		return c.Always
+	case "Capture":
+		return c.Capture
 	case "Certs":
 		return c.Certs
+	case "ClientCert":
+		return c.ClientCert
+	case "ClientKey":
+		return c.ClientKey
+	case "ClientKeyPass":
+		return c.ClientKeyPass
+	case "ServerName":
+		return c.ServerName
+	case "TLSMinVersion":
+		return c.TLSMinVersion
+	case "Proxy":
+		return c.Proxy
+	case "NoProxy":
+		return c.NoProxy
 	case "SetVariables":
 		return c.Vars
 	case "SetEnvironments":
@@ -547,6 +921,18 @@ func (c *GRPCConfig) Apply(start, end int, key string, val []byte) {
 		c.Data = splice(c.Data, val, start, end)
 	case "Timeout":
 		c.Timeout = splice(c.Timeout, val, start, end)
+	case "Retry":
+		c.Retry = splice(c.Retry, val, start, end)
+	case "Until":
+		c.Until = splice(c.Until, val, start, end)
+	case "Interval":
+		c.Interval = splice(c.Interval, val, start, end)
+	case "MaxWait":
+		c.MaxWait = splice(c.MaxWait, val, start, end)
+	case "DataFile":
+		c.DataFile = splice(c.DataFile, val, start, end)
+	case "ForEach":
+		c.ForEach = splice(c.ForEach, val, start, end)
 	case "Metadata":
 		c.Metadata = splice(c.Metadata, val, start, end)
 	case "ProtoPath":
@@ -559,8 +945,30 @@ func (c *GRPCConfig) Apply(start, end int, key string, val []byte) {
 		c.Wait = splice(c.Wait, val, start, end)
 	case "Expect":
 		c.Expect = splice(c.Expect, val, start, end)
+	case "Assert":
+		c.Assert = splice(c.Assert, val, start, end)
+	case "If":
+		c.If = splice(c.If, val, start, end)
+	case "Always":
+		c.Always = splice(c.Always, val, start, end)
+	case "Capture":
+		c.Capture = splice(c.Capture, val, start, end)
 	case "Certs":
 		c.Certs = splice(c.Certs, val, start, end)
+	case "ClientCert":
+		c.ClientCert = splice(c.ClientCert, val, start, end)
+	case "ClientKey":
+		c.ClientKey = splice(c.ClientKey, val, start, end)
+	case "ClientKeyPass":
+		c.ClientKeyPass = splice(c.ClientKeyPass, val, start, end)
+	case "ServerName":
+		c.ServerName = splice(c.ServerName, val, start, end)
+	case "TLSMinVersion":
+		c.TLSMinVersion = splice(c.TLSMinVersion, val, start, end)
+	case "Proxy":
+		c.Proxy = splice(c.Proxy, val, start, end)
+	case "NoProxy":
+		c.NoProxy = splice(c.NoProxy, val, start, end)
 	case "SetVariables":
 		c.Vars = splice(c.Vars, val, start, end)
 	case "SetEnvironments":
@@ -620,7 +1028,19 @@ func (c *RepeatConfig) Clone() Config {
 	*newCfg = *c
 	newCfg.Wait = cloneBytes(c.Wait)
 	newCfg.Timeout = cloneBytes(c.Timeout)
+	newCfg.Retry = cloneBytes(c.Retry)
+	newCfg.Until = cloneBytes(c.Until)
+	newCfg.Interval = cloneBytes(c.Interval)
+	newCfg.MaxWait = cloneBytes(c.MaxWait)
+	newCfg.DataFile = cloneBytes(c.DataFile)
+	newCfg.ForEach = cloneBytes(c.ForEach)
 	newCfg.Expect = cloneBytes(c.Expect)
+	newCfg.Assert = cloneBytes(c.Assert)
+	newCfg.If = cloneBytes(c.If)
+	newCfg.Always = cloneBytes(c.Always)
+	newCfg.Capture = cloneBytes(c.Capture)
+	newCfg.Group = cloneBytes(c.Group)
+	newCfg.After = cloneBytes(c.After)
 	newCfg.Certs = cloneBytes(c.Certs)
 	newCfg.Vars = cloneBytes(c.Vars)
 	newCfg.Envs = cloneBytes(c.Envs)
@@ -647,8 +1067,28 @@ func (c *RepeatConfig) GetRaw(key string) []byte {
 		return c.Wait
 	case "Timeout":
 		return c.Timeout
+	case "Retry":
+		return c.Retry
+	case "Until":
+		return c.Until
+	case "Interval":
+		return c.Interval
+	case "MaxWait":
+		return c.MaxWait
+	case "DataFile":
+		return c.DataFile
+	case "ForEach":
+		return c.ForEach
 	case "Expect":
 		return c.Expect
+	case "Assert":
+		return c.Assert
+	case "If":
+		return c.If
+	case "Always":
+		return c.Always
+	case "Capture":
+		return c.Capture
 	case "Certs":
 		return c.Certs
 	case "SetVariables":
@@ -671,8 +1111,28 @@ func (c *RepeatConfig) Apply(start, end int, key string, val []byte) {
 		c.Wait = splice(c.Wait, val, start, end)
 	case "Timeout":
 		c.Timeout = splice(c.Timeout, val, start, end)
+	case "Retry":
+		c.Retry = splice(c.Retry, val, start, end)
+	case "Until":
+		c.Until = splice(c.Until, val, start, end)
+	case "Interval":
+		c.Interval = splice(c.Interval, val, start, end)
+	case "MaxWait":
+		c.MaxWait = splice(c.MaxWait, val, start, end)
+	case "DataFile":
+		c.DataFile = splice(c.DataFile, val, start, end)
+	case "ForEach":
+		c.ForEach = splice(c.ForEach, val, start, end)
 	case "Expect":
 		c.Expect = splice(c.Expect, val, start, end)
+	case "Assert":
+		c.Assert = splice(c.Assert, val, start, end)
+	case "If":
+		c.If = splice(c.If, val, start, end)
+	case "Always":
+		c.Always = splice(c.Always, val, start, end)
+	case "Capture":
+		c.Capture = splice(c.Capture, val, start, end)
 	case "Certs":
 		c.Certs = splice(c.Certs, val, start, end)
 	case "SetVariables":
@@ -701,7 +1161,19 @@ func (c *ImportConfig) Clone() Config {
 	*newCfg = *c
 	newCfg.Wait = cloneBytes(c.Wait)
 	newCfg.Timeout = cloneBytes(c.Timeout)
+	newCfg.Retry = cloneBytes(c.Retry)
+	newCfg.Until = cloneBytes(c.Until)
+	newCfg.Interval = cloneBytes(c.Interval)
+	newCfg.MaxWait = cloneBytes(c.MaxWait)
+	newCfg.DataFile = cloneBytes(c.DataFile)
+	newCfg.ForEach = cloneBytes(c.ForEach)
 	newCfg.Expect = cloneBytes(c.Expect)
+	newCfg.Assert = cloneBytes(c.Assert)
+	newCfg.If = cloneBytes(c.If)
+	newCfg.Always = cloneBytes(c.Always)
+	newCfg.Capture = cloneBytes(c.Capture)
+	newCfg.Group = cloneBytes(c.Group)
+	newCfg.After = cloneBytes(c.After)
 	newCfg.TargetPath = c.TargetPath
 	newCfg.Certs = cloneBytes(c.Certs)
 	newCfg.Vars = cloneBytes(c.Vars)
@@ -722,8 +1194,28 @@ func (c *ImportConfig) GetRaw(key string) []byte {
 		return c.Wait
 	case "Timeout":
 		return c.Timeout
+	case "Retry":
+		return c.Retry
+	case "Until":
+		return c.Until
+	case "Interval":
+		return c.Interval
+	case "MaxWait":
+		return c.MaxWait
+	case "DataFile":
+		return c.DataFile
+	case "ForEach":
+		return c.ForEach
 	case "Expect":
 		return c.Expect
+	case "Assert":
+		return c.Assert
+	case "If":
+		return c.If
+	case "Always":
+		return c.Always
+	case "Capture":
+		return c.Capture
 	case "Certs":
 		return c.Certs
 	case "SetVariables":
@@ -740,8 +1232,28 @@ func (c *ImportConfig) Apply(start, end int, key string, val []byte) {
 		c.Wait = splice(c.Wait, val, start, end)
 	case "Timeout":
 		c.Timeout = splice(c.Timeout, val, start, end)
+	case "Retry":
+		c.Retry = splice(c.Retry, val, start, end)
+	case "Until":
+		c.Until = splice(c.Until, val, start, end)
+	case "Interval":
+		c.Interval = splice(c.Interval, val, start, end)
+	case "MaxWait":
+		c.MaxWait = splice(c.MaxWait, val, start, end)
+	case "DataFile":
+		c.DataFile = splice(c.DataFile, val, start, end)
+	case "ForEach":
+		c.ForEach = splice(c.ForEach, val, start, end)
 	case "Expect":
 		c.Expect = splice(c.Expect, val, start, end)
+	case "Assert":
+		c.Assert = splice(c.Assert, val, start, end)
+	case "If":
+		c.If = splice(c.If, val, start, end)
+	case "Always":
+		c.Always = splice(c.Always, val, start, end)
+	case "Capture":
+		c.Capture = splice(c.Capture, val, start, end)
 	case "Certs":
 		c.Certs = splice(c.Certs, val, start, end)
 	case "SetVariables":
@@ -751,6 +1263,15 @@ func (c *ImportConfig) Apply(start, end int, key string, val []byte) {
 	}
 }
 
+// stripDefaults restores own values of fields changed by defaults.
+func stripDefaults(c Config, own map[string][]byte) {
+	for key, val := range own {
+		// first splice empties field, so second one sets own value as is
+		c.Apply(0, MaxLen, key, nil)
+		c.Apply(0, MaxLen, key, val)
+	}
+}
+
 // splice accepts original data, new value, start and end.
 // It splices value to original data.
 func splice(orig, val []byte, start, end int) []byte {
@@ -0,0 +1,482 @@
+// Package core bench.go contains load testing of one config.
+// Config is sent repeatedly by workers and latencies are collected.
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Votline/Gurl-cli/internal/config"
+	"github.com/Votline/Gurl-cli/internal/parser"
+	"github.com/Votline/Gurl-cli/internal/transport"
+
+	"github.com/Votline/Gurlf"
+	"go.uber.org/zap"
+)
+
+// benchDefDuration is a bench duration if duration and requests are not set.
+const benchDefDuration = 10 * time.Second
+
+// benchBuckets is a count of histogram buckets.
+const benchBuckets = 10
+
+// benchErrKey is a key of transport errors in status codes.
+const benchErrKey = "error"
+
+// BenchOptions is a options of bench command.
+type BenchOptions struct {
+	// ID is a id of config for bench.
+	ID int
+
+	// Concurrency is a count of workers.
+	Concurrency int
+
+	// Duration is a bench duration. Used if Requests is not set.
+	Duration time.Duration
+
+	// Requests is a total count of requests.
+	Requests int
+
+	// RPS is a max count of requests per second. Unlimited if not set.
+	RPS int
+
+	// Summary is a path of JSON summary file.
+	Summary string
+}
+
+// benchStats is a stats of one worker.
+type benchStats struct {
+	lat    []time.Duration
+	codes  map[string]int
+	failed int
+}
+
+// benchSummary is a result of bench.
+// Printed to console and written to summary file.
+type benchSummary struct {
+	ID          int            `json:"id"`
+	Name        string         `json:"name"`
+	Type        string         `json:"type"`
+	Concurrency int            `json:"concurrency"`
+	Requests    int            `json:"requests"`
+	Failed      int            `json:"failed"`
+	DurationMs  float64        `json:"duration_ms"`
+	RPS         float64        `json:"rps"`
+	Latency     benchLatency   `json:"latency_ms"`
+	Codes       map[string]int `json:"codes"`
+	Histogram   []benchBucket  `json:"histogram"`
+}
+
+// benchLatency is a latency percentiles in milliseconds.
+type benchLatency struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// benchBucket is a one bucket of latency histogram.
+type benchBucket struct {
+	// UpTo is a upper bound of bucket in milliseconds.
+	UpTo  float64 `json:"le_ms"`
+	Count int     `json:"count"`
+}
+
+// Bench accepts config path and options.
+// It sends config with id from options repeatedly and prints latency summary.
+// Instructions are resolved for each request,
+// responses of other configs are taken from 'Response' field of file.
+func Bench(cPath string, opts Options, log *zap.Logger) error {
+	const op = "core.Bench"
+
+	bo := opts.Bench
+	if bo.Concurrency < 1 {
+		bo.Concurrency = 1
+	}
+	if bo.Requests == 0 && bo.Duration == 0 {
+		bo.Duration = benchDefDuration
+	}
+
+	config.Init()
+	sData, err := gurlf.ScanFile(cPath)
+	if err != nil {
+		return fmt.Errorf("%s: scan file %q: %w", op, cPath, err)
+	}
+
+	defs, err := parser.ParseDefaults(&sData, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	cfgs := make([]config.Config, 0, len(sData))
+	if err := parser.ParseStream(&sData, defs, func(cfg config.Config) {
+		cfgs = append(cfgs, allocConfig(cfg))
+		cfg.Release()
+	}, log); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if bo.ID < 0 || bo.ID >= len(cfgs) {
+		return fmt.Errorf("%s: config with id %d not found", op, bo.ID)
+	}
+	target := cfgs[bo.ID]
+	execTarget := target.UnwrapExec()
+	switch execTarget.(type) {
+	case *config.HTTPConfig, *config.GRPCConfig:
+	default:
+		return fmt.Errorf("%s: config %d: type %q can't be benched", op, bo.ID, target.GetType())
+	}
+	if field := benchUnsupported(target, execTarget); field != "" {
+		return fmt.Errorf("%s: config %d: field %q is not supported by bench", op, bo.ID, field)
+	}
+
+	prof, err := loadProfile(cPath, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	prof.print()
+	vars := prof.newVars()
+	resHub := make([]*transport.Result, len(cfgs))
+	for i, cfg := range cfgs[:bo.ID+1] {
+		if ok := applyVars(cfg, vars, log); !ok {
+			return fmt.Errorf("%s: config %d: invalid variables", op, i)
+		}
+		resHub[i] = storedResult(cfg)
+	}
+
+	ctx := context.Background()
+	if bo.Requests == 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, bo.Duration)
+		defer cancel()
+	}
+
+	var tick <-chan time.Time
+	if bo.RPS > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(bo.RPS))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var issued atomic.Int64
+	take := func() bool {
+		if bo.Requests > 0 {
+			return issued.Add(1) <= int64(bo.Requests)
+		}
+		return ctx.Err() == nil
+	}
+
+	ses, err := transport.NewSession(opts.CookieJar, opts.Proxy, log)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err := ses.Close(); err != nil {
+			log.Error("Failed to close session",
+				zap.String("op", op),
+				zap.Error(err))
+		}
+	}()
+	trnsp := transport.NewTransport(func(*transport.Result) {}, ses, log)
+	stats := make([]benchStats, bo.Concurrency)
+
+	fmt.Printf("\033[90mBench [ID %d] %s: %d workers, ", bo.ID, target.GetName(), bo.Concurrency)
+	if bo.Requests > 0 {
+		fmt.Printf("%d requests", bo.Requests)
+	} else {
+		fmt.Printf("%s", bo.Duration)
+	}
+	if bo.RPS > 0 {
+		fmt.Printf(", %d req/s", bo.RPS)
+	}
+	fmt.Println("\033[0m")
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for w := range stats {
+		st := &stats[w]
+		st.codes = make(map[string]int)
+
+		wg.Go(func() {
+			for take() {
+				if tick != nil {
+					select {
+					case <-tick:
+					case <-ctx.Done():
+						return
+					}
+				}
+
+				cfg := allocConfig(target)
+				execCfg := cfg.UnwrapExec()
+				applyDeps(cfg, &resHub, vars, nil, false, log)
+				applyCerts(cfg, execCfg, log)
+				if t := cfg.GetTimeout(); t != nil {
+					execCfg.SetTimeout(t)
+				}
+
+				res := new(transport.Result)
+				reqStart := time.Now()
+				err := benchSend(execCfg, trnsp, res)
+				st.lat = append(st.lat, time.Since(reqStart))
+
+				key, failed := benchOutcome(cfg, execCfg, res, err)
+				st.codes[key]++
+				if failed {
+					st.failed++
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	sum := summarize(stats, time.Since(start))
+	sum.ID = bo.ID
+	sum.Name = target.GetName()
+	sum.Type = execTarget.GetType()
+	sum.Concurrency = bo.Concurrency
+
+	printBench(sum)
+
+	if bo.Summary != "" {
+		data, err := json.MarshalIndent(sum, "", "  ")
+		if err != nil {
+			return fmt.Errorf("%s: marshal summary: %w", op, err)
+		}
+		if err := os.WriteFile(bo.Summary, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("%s: write summary (path=%q): %w", op, bo.Summary, err)
+		}
+	}
+
+	return nil
+}
+
+// storedResult returns result from 'Response' field of config.
+// Used for instructions of bench config.
+func storedResult(cfg config.Config) *transport.Result {
+	res := new(transport.Result)
+
+	var raw []byte
+	switch v := cfg.UnwrapExec().(type) {
+	case *config.HTTPConfig:
+		raw = v.Resp
+		res.Cookie = v.CookieOut
+	case *config.GRPCConfig:
+		raw = v.Resp
+	}
+
+	if r, ok := cfg.(*config.RepeatConfig); ok {
+		raw = r.Resp
+	}
+
+	res.Raw, res.Attempts = storedResponse(raw)
+	res.Size = len(res.Raw)
+	return res
+}
+
+// benchUnsupported returns name of field which bench can't apply, empty if there is no such field.
+// Bench sends config once per request, so retries, polling, conditions and rows are rejected.
+func benchUnsupported(cfg, execCfg config.Config) string {
+	for _, c := range []config.Config{cfg, execCfg} {
+		switch {
+		case c.GetRetry() != nil:
+			return "Retry"
+		case c.GetUntil() != nil:
+			return "Until"
+		case c.GetIf() != nil:
+			return "If"
+		case c.GetDataFile() != nil:
+			return "DataFile"
+		case c.GetForEach() != nil:
+			return "ForEach"
+		}
+	}
+	return ""
+}
+
+// benchSend sends config without logging of each request.
+func benchSend(execCfg config.Config, trnsp *transport.Transport, res *transport.Result) error {
+	switch v := execCfg.(type) {
+	case *config.HTTPConfig:
+		return trnsp.DoHTTP(v, res, true)
+	case *config.GRPCConfig:
+		return trnsp.DoGRPC(v, res)
+	}
+	return nil
+}
+
+// benchOutcome returns status key of result and failure flag.
+// Result is failed if 'Expect' is not passed.
+// Without 'Expect' HTTP codes from 400 and gRPC codes except OK are failed.
+func benchOutcome(cfg, execCfg config.Config, res *transport.Result, err error) (string, bool) {
+	if err != nil {
+		return benchErrKey, true
+	}
+
+	key := strconv.Itoa(res.Info.Code)
+
+	expect := cfg.GetExpect()
+	if expect == nil {
+		expect = execCfg.GetExpect()
+	}
+	if expect != nil {
+		return key, parser.ParseExpect(expect, res.Info.Code) != parser.ExpectDone
+	}
+
+	if res.Info.ConfigType == "grpc" {
+		return key, res.Info.Code != 0
+	}
+	return key, res.Info.Code >= 400
+}
+
+// summarize merges stats of workers.
+func summarize(stats []benchStats, elapsed time.Duration) benchSummary {
+	sum := benchSummary{
+		DurationMs: ms(elapsed),
+		Codes:      make(map[string]int),
+	}
+
+	var lat []time.Duration
+	for _, st := range stats {
+		lat = append(lat, st.lat...)
+		sum.Failed += st.failed
+		for k, v := range st.codes {
+			sum.Codes[k] += v
+		}
+	}
+
+	sum.Requests = len(lat)
+	if elapsed > 0 {
+		sum.RPS = float64(sum.Requests) / elapsed.Seconds()
+	}
+	if len(lat) == 0 {
+		return sum
+	}
+
+	sort.Slice(lat, func(i, j int) bool { return lat[i] < lat[j] })
+
+	var total time.Duration
+	for _, l := range lat {
+		total += l
+	}
+
+	sum.Latency = benchLatency{
+		Min:  ms(lat[0]),
+		Mean: ms(total / time.Duration(len(lat))),
+		P50:  ms(percentile(lat, 50)),
+		P90:  ms(percentile(lat, 90)),
+		P99:  ms(percentile(lat, 99)),
+		Max:  ms(lat[len(lat)-1]),
+	}
+	sum.Histogram = histogram(lat)
+
+	return sum
+}
+
+// percentile returns percentile of sorted latencies.
+func percentile(lat []time.Duration, p float64) time.Duration {
+	idx := int(math.Ceil(p/100*float64(len(lat)))) - 1
+	idx = max(0, min(idx, len(lat)-1))
+	return lat[idx]
+}
+
+// histogram splits sorted latencies to equal buckets from min to max.
+func histogram(lat []time.Duration) []benchBucket {
+	lo, hi := lat[0], lat[len(lat)-1]
+	if lo == hi {
+		return []benchBucket{{UpTo: ms(hi), Count: len(lat)}}
+	}
+
+	step := (hi - lo) / benchBuckets
+	if step == 0 {
+		step = 1
+	}
+
+	buckets := make([]benchBucket, benchBuckets)
+	for i := range buckets {
+		buckets[i].UpTo = ms(lo + step*time.Duration(i+1))
+	}
+	buckets[len(buckets)-1].UpTo = ms(hi)
+
+	for _, l := range lat {
+		i := min(int((l-lo)/step), len(buckets)-1)
+		buckets[i].Count++
+	}
+
+	return buckets
+}
+
+// printBench prints bench summary.
+func printBench(sum benchSummary) {
+	fmt.Println(strings.Repeat("-", 20))
+	fmt.Printf("\n\033[90m[ID %d]\033[0m %s \033[90m(%s)\033[0m\n", sum.ID, sum.Name, sum.Type)
+
+	color := "\033[32m"
+	if sum.Failed != 0 {
+		color = "\033[31m"
+	}
+	fmt.Printf("  Requests:    %d %s(%d failed)\033[0m\n", sum.Requests, color, sum.Failed)
+	fmt.Printf("  Duration:    %s\n", time.Duration(sum.DurationMs*float64(time.Millisecond)).Round(time.Millisecond))
+	fmt.Printf("  Throughput:  %.2f req/s\n", sum.RPS)
+
+	fmt.Printf("\nStatus codes:\n")
+	keys := make([]string, 0, len(sum.Codes))
+	for k := range sum.Codes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name := strings.ToUpper(sum.Type) + " " + k
+		if k == benchErrKey {
+			name = benchErrKey
+		}
+		fmt.Printf("  %-10s %d\n", name, sum.Codes[k])
+	}
+
+	if sum.Requests == 0 {
+		return
+	}
+
+	fmt.Printf("\nLatency:\n")
+	fmt.Printf("  min   %s\n", fmtMs(sum.Latency.Min))
+	fmt.Printf("  mean  %s\n", fmtMs(sum.Latency.Mean))
+	fmt.Printf("  p50   %s\n", fmtMs(sum.Latency.P50))
+	fmt.Printf("  p90   %s\n", fmtMs(sum.Latency.P90))
+	fmt.Printf("  p99   %s\n", fmtMs(sum.Latency.P99))
+	fmt.Printf("  max   %s\n", fmtMs(sum.Latency.Max))
+
+	fmt.Printf("\nHistogram:\n")
+	top := 0
+	for _, b := range sum.Histogram {
+		top = max(top, b.Count)
+	}
+	for _, b := range sum.Histogram {
+		bar := 0
+		if top > 0 {
+			bar = b.Count * 40 / top
+		}
+		fmt.Printf("  %10s [%6d] \033[90m%s\033[0m\n",
+			fmtMs(b.UpTo), b.Count, strings.Repeat("■", bar))
+	}
+}
+
+// ms converts duration to milliseconds.
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// fmtMs formats milliseconds as duration.
+func fmtMs(v float64) string {
+	return time.Duration(v * float64(time.Millisecond)).Round(time.Microsecond).String()
+}
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Votline/Gurl-cli/internal/config"
+)
+
+// msLat converts milliseconds to latencies.
+func msLat(vals ...int) []time.Duration {
+	lat := make([]time.Duration, len(vals))
+	for i, v := range vals {
+		lat[i] = time.Duration(v) * time.Millisecond
+	}
+	return lat
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		stats     []benchStats
+		requests  int
+		failed    int
+		codes     map[string]int
+		latency   benchLatency
+		histogram []benchBucket
+	}{
+		{
+			[]benchStats{
+				{lat: msLat(10, 3, 5, 1, 7), codes: map[string]int{"200": 4, "500": 1}, failed: 1},
+				{lat: msLat(2, 9, 4, 6, 8), codes: map[string]int{"200": 4, benchErrKey: 1}, failed: 1},
+			},
+			10, 2,
+			map[string]int{"200": 8, "500": 1, benchErrKey: 1},
+			benchLatency{Min: 1, Mean: 5.5, P50: 5, P90: 9, P99: 10, Max: 10},
+			[]benchBucket{
+				{1.9, 1}, {2.8, 1}, {3.7, 1}, {4.6, 1}, {5.5, 1},
+				{6.4, 1}, {7.3, 1}, {8.2, 1}, {9.1, 1}, {10, 1},
+			},
+		},
+		{
+			[]benchStats{{lat: msLat(7), codes: map[string]int{"200": 1}}},
+			1, 0,
+			map[string]int{"200": 1},
+			benchLatency{Min: 7, Mean: 7, P50: 7, P90: 7, P99: 7, Max: 7},
+			[]benchBucket{{7, 1}},
+		},
+		{
+			[]benchStats{
+				{lat: msLat(4, 4), codes: map[string]int{"200": 2}},
+				{lat: msLat(4), codes: map[string]int{"200": 1}},
+			},
+			3, 0,
+			map[string]int{"200": 3},
+			benchLatency{Min: 4, Mean: 4, P50: 4, P90: 4, P99: 4, Max: 4},
+			[]benchBucket{{4, 3}},
+		},
+		{
+			[]benchStats{{codes: map[string]int{}}},
+			0, 0,
+			map[string]int{},
+			benchLatency{},
+			nil,
+		},
+	}
+
+	for i, tt := range tests {
+		sum := summarize(tt.stats, time.Second)
+		if sum.Requests != tt.requests || sum.Failed != tt.failed {
+			t.Errorf("[%d]: expected %d requests, %d failed, but got %d, %d",
+				i, tt.requests, tt.failed, sum.Requests, sum.Failed)
+		}
+		if sum.RPS != float64(tt.requests) {
+			t.Errorf("[%d]: expected rps %d, but got %v", i, tt.requests, sum.RPS)
+		}
+		if !maps.Equal(sum.Codes, tt.codes) {
+			t.Errorf("[%d]: expected codes %v, but got %v", i, tt.codes, sum.Codes)
+		}
+		if sum.Latency != tt.latency {
+			t.Errorf("[%d]: expected latency %+v, but got %+v", i, tt.latency, sum.Latency)
+		}
+		if !slices.Equal(sum.Histogram, tt.histogram) {
+			t.Errorf("[%d]: expected histogram %v, but got %v", i, tt.histogram, sum.Histogram)
+		}
+	}
+}
+
+func BenchmarkSummarize(b *testing.B) {
+	stats := []benchStats{
+		{lat: msLat(10, 3, 5, 1, 7), codes: map[string]int{"200": 5}},
+		{lat: msLat(2, 9, 4, 6, 8), codes: map[string]int{"200": 5}},
+	}
+	for b.Loop() {
+		summarize(stats, time.Second)
+	}
+}
+
+func TestStoredResult(t *testing.T) {
+	tests := []struct {
+		resp     string
+		inst     string
+		expected string
+		attempts int
+	}{
+		{`{"token":"abc"}`, `{RESPONSE id=0 json:token}`, `abc`, 1},
+		{"[attempts 3]\n{\"token\":\"abc\"}", `{RESPONSE id=0 json:token}`, `abc`, 3},
+		{"[attempts 3]\nplain", `{RESPONSE id=0}`, `plain`, 3},
+		{"[attempts x]\nplain", `{RESPONSE id=0}`, "[attempts x]\nplain", 1},
+		{``, `{RESPONSE id=0}`, ``, 1},
+	}
+
+	from := depBindings["RESPONSE"].From
+	for i, tt := range tests {
+		cfg := &config.HTTPConfig{}
+		cfg.Resp = []byte(tt.resp)
+
+		res := storedResult(cfg)
+		if res.Attempts != tt.attempts {
+			t.Errorf("[%d]: expected %d attempts, but got %d", i, tt.attempts, res.Attempts)
+		}
+		if val := from(res, []byte(tt.inst)); string(val) != tt.expected {
+			t.Errorf("[%d]: expected %q, but got %q", i, tt.expected, string(val))
+		}
+
+		// response written back by run is read by bench as is
+		if tt.attempts > 1 {
+			stored := &config.HTTPConfig{}
+			stored.Resp = fileResponse(res)
+			if back := storedResult(stored); string(back.Raw) != string(res.Raw) || back.Attempts != res.Attempts {
+				t.Errorf("[%d]: expected %q, but got %q", i, res.Raw, back.Raw)
+			}
+		}
+	}
+}
+
+func BenchmarkStoredResult(b *testing.B) {
+	cfg := &config.HTTPConfig{}
+	cfg.Resp = []byte("[attempts 3]\n{\"token\":\"abc\"}")
+	for b.Loop() {
+		storedResult(cfg)
+	}
+}
+
+func TestBenchUnsupported(t *testing.T) {
+	tests := []struct {
+		set      func(c *config.HTTPConfig)
+		expected string
+	}{
+		{func(c *config.HTTPConfig) {}, ""},
+		{func(c *config.HTTPConfig) { c.Timeout = []byte("1s") }, ""},
+		{func(c *config.HTTPConfig) { c.Retry = []byte("3") }, "Retry"},
+		{func(c *config.HTTPConfig) { c.Until = []byte("status == 200") }, "Until"},
+		{func(c *config.HTTPConfig) { c.If = []byte("1 == 1") }, "If"},
+		{func(c *config.HTTPConfig) { c.DataFile = []byte("users.csv") }, "DataFile"},
+		{func(c *config.HTTPConfig) { c.ForEach = []byte("{RESPONSE id=0 json:items} as ID") }, "ForEach"},
+	}
+
+	for i, tt := range tests {
+		cfg := &config.HTTPConfig{}
+		tt.set(cfg)
+		if field := benchUnsupported(cfg, cfg); field != tt.expected {
+			t.Errorf("[%d]: expected %q, but got %q", i, tt.expected, field)
+		}
+	}
+}
+
+func BenchmarkBenchUnsupported(b *testing.B) {
+	cfg := &config.HTTPConfig{}
+	for b.Loop() {
+		benchUnsupported(cfg, cfg)
+	}
+}
@@ -4,11 +4,18 @@ package core
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -28,23 +35,32 @@ import (
 // It need for print ignore.
 const importConfigCode = -1000
 
+// Default values of 'Until' polling.
+const (
+	untilInterval = time.Second
+	untilMaxWait  = 30 * time.Second
+)
+
 // DepBindigs is a struct for dependency bindings.
 type DepBindigs struct {
-	From func(res *transport.Result) []byte
+	From func(res *transport.Result, inst []byte) []byte
 	To   func(cfg config.Config, start, end int, key string, val, inst []byte)
 }
 
 // depBindings is a map for dependency bindings.
 var depBindings = map[string]DepBindigs{
 	"RESPONSE": {
-		From: func(res *transport.Result) []byte { return res.Raw },
+		From: func(res *transport.Result, inst []byte) []byte {
+			kind, arg := parser.ParseResponseKind(inst)
+			val, _ := resultValue(res, kind, arg)
+			return val
+		},
 		To: func(cfg config.Config, s, e int, k string, v, inst []byte) {
-			parser.ParseResponse(&v, inst)
 			cfg.Apply(s, e, k, v)
 		},
 	},
 	"COOKIES": {
-		From: func(res *transport.Result) []byte { return res.Cookie },
+		From: func(res *transport.Result, inst []byte) []byte { return res.Cookie },
 		To: func(cfg config.Config, s, e int, k string, v, inst []byte) {
 			cfg.Apply(s, e, k, v)
 			cfg.SetFlag(config.FlagUseFileCookies)
@@ -52,49 +68,221 @@ var depBindings = map[string]DepBindigs{
 	},
 }
 
-// Start accepts config type, path and create flag.
+// Options is a options of run.
+type Options struct {
+	// DisablePrint disables printing of responses.
+	DisablePrint bool
+
+	// Verbose enables printing of connection details, like TLS handshake.
+	Verbose bool
+
+	// Reports is a list of report files. Like 'junit=report.xml'.
+	Reports []string
+
+	// Parallel is a max count of configs executed at once.
+	// Configs are executed one by one if it is less than 2.
+	Parallel int
+
+	// Bench is a options of bench command.
+	Bench BenchOptions
+
+	// Profile is a name of profile in profiles file. Like 'staging'.
+	Profile string
+
+	// Profiles is a path to profiles file.
+	// By default 'profiles.gurlf' next to config file.
+	Profiles string
+
+	// Vars is a variables from command line. Like 'key=value'.
+	// They override values of profile.
+	Vars []string
+
+	// CookieJar is a path to cookie jar file.
+	// Cookies are loaded before run and saved after it.
+	CookieJar string
+
+	// Proxy is a proxy URL for all configs.
+	// Proxy env vars are used if it is empty.
+	Proxy string
+
+	// NoWrite disables writing of responses back to config files.
+	// It is set by Test, so suites stay unchanged.
+	NoWrite bool
+}
+
+// Start accepts config type, path, create flag and options.
 // It entry point for Gurl-cli.
-func Start(cType, cPath string, cCreate, disablePrint bool, log *zap.Logger) error {
+func Start(cType, cPath string, cCreate bool, opts Options, log *zap.Logger) error {
+	const op = "core.Start"
+
 	if cCreate {
 		return config.Create(cType, cPath)
 	}
+
+	specs, err := parseReports(opts.Reports)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	prof, err := loadProfile(cPath, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	prof.print()
+
 	config.Init()
-	vars := make(map[string][]byte)
-	return handleConfig(cPath, disablePrint, vars, log)
+	vars := prof.newVars()
+	rep := &Report{}
+	ses, err := transport.NewSession(opts.CookieJar, opts.Proxy, log)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = handleConfig(cPath, opts, vars, nil, ses, rep, log)
+	if err != nil {
+		rep.add(Case{File: cPath, ID: -1, Outcome: OutcomeError, Message: err.Error()})
+	}
+	if cErr := ses.Close(); cErr != nil {
+		log.Error("Failed to close session",
+			zap.String("op", op),
+			zap.Error(cErr))
+		rep.add(Case{File: cPath, ID: -1, Outcome: OutcomeError, Message: cErr.Error()})
+		if err == nil {
+			err = fmt.Errorf("%s: %w", op, cErr)
+		}
+	}
+
+	if wErr := writeReports(specs, rep.Cases()); wErr != nil {
+		log.Error("Failed to write report",
+			zap.String("op", op),
+			zap.Error(wErr))
+		if err == nil {
+			err = fmt.Errorf("%s: %w", op, wErr)
+		}
+	}
+
+	return err
 }
 
-// handleConfig accepts config path and print flag.
+// Test accepts path to file or directory and options.
+// It runs every '.gurlf' file in isolation and prints summary.
+// Files are not changed, responses aren't written back.
+// Returns error if any config failed, crashed or can't be sent.
+func Test(tPath string, opts Options, log *zap.Logger) error {
+	const op = "core.Test"
+
+	opts.NoWrite = true
+
+	specs, err := parseReports(opts.Reports)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	prof, err := loadProfile(tPath, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	files, err := findSuites(tPath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	files = slices.DeleteFunc(files, func(f string) bool {
+		return filepath.Clean(f) == filepath.Clean(prof.path)
+	})
+	if len(files) == 0 {
+		return fmt.Errorf("%s: no config files in %q", op, tPath)
+	}
+	prof.print()
+
+	var all []Case
+	failed := 0
+	for _, f := range files {
+		config.Init()
+		vars := prof.newVars()
+		rep := &Report{}
+		ses, err := transport.NewSession(opts.CookieJar, opts.Proxy, log)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if _, err := handleConfig(f, opts, vars, nil, ses, rep, log); err != nil {
+			log.Error("Failed to handle config",
+				zap.String("op", op),
+				zap.String("path", f),
+				zap.Error(err))
+			rep.add(Case{File: f, ID: -1, Outcome: OutcomeError, Message: err.Error()})
+		}
+		if err := ses.Close(); err != nil {
+			log.Error("Failed to close session",
+				zap.String("op", op),
+				zap.String("path", f),
+				zap.Error(err))
+			rep.add(Case{File: f, ID: -1, Outcome: OutcomeError, Message: err.Error()})
+		}
+
+		cases := rep.Cases()
+		printCases(f, cases)
+		all = append(all, cases...)
+		failed += rep.Failed()
+	}
+
+	printTotal(len(files), len(all), failed)
+
+	if err := writeReports(specs, all); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if failed != 0 {
+		return fmt.Errorf("%s: %d of %d configs failed", op, failed, len(all))
+	}
+	return nil
+}
+
+// handleConfig accepts config path and options.
 // It main processing function.
 // It scans config file, parses it, sends configs and update file.
-// Can be used recursively.
-func handleConfig(cPath string, disablePrint bool, vars map[string][]byte, log *zap.Logger) error {
+// Outcome of each config is added to report.
+// Can be used recursively, defs are defaults of importing file.
+// Session is shared with imported files, so they reuse connections.
+// Returns results of file for configs which import it.
+func handleConfig(cPath string, opts Options, vars map[string][]byte, defs *parser.Defaults, ses *transport.Session, rep *Report, log *zap.Logger) (*transport.Results, error) {
 	const op = "core.handleConfig"
 
+	disablePrint := opts.DisablePrint
+
 	var sData []gscan.Data
 	soloCfg := false
 
 	if _, err := os.Stat(cPath); err == nil {
 		sData, err = gurlf.ScanFile(cPath)
 		if err != nil {
-			return fmt.Errorf("%s: scan file %q: %w", op, cPath, err)
+			return nil, fmt.Errorf("%s: scan file %q: %w", op, cPath, err)
 		}
 	} else {
 		oneCfg := unsafe.Slice(unsafe.StringData(cPath), len(cPath))
 		sData, err = gurlf.Scan(oneCfg)
 		if err != nil {
-			return fmt.Errorf("%s: scan %q: %w", op, cPath, err)
+			return nil, fmt.Errorf("%s: scan %q: %w", op, cPath, err)
 		}
 		soloCfg = true
 	}
 
-	resHub := make([]*transport.Result, 0, len(sData))
+	defs, err := parser.ParseDefaults(&sData, defs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// resHub is indexed by config id, so skipped configs don't shift references.
+	resHub := make([]*transport.Result, len(sData))
 	cfgFileRBuf := buffer.NewRb[config.Config]()
 	parserRBuf := buffer.NewRb[config.Config]()
 	transportRBuf := buffer.NewRb[*transport.Result]()
 	resPrintBuf := buffer.NewRb[*transport.Result]()
-	trnsp := transport.NewTransport(transportRBuf.Write, log)
+	trnsp := transport.NewTransport(transportRBuf.Write, ses, log)
 
-	if soloCfg {
+	// responses of single config and of test run aren't written back
+	noWrite := soloCfg || opts.NoWrite
+	if noWrite {
 		cfgFileRBuf = buffer.NewNop[config.Config]()
 	}
 
@@ -105,131 +293,204 @@ func handleConfig(cPath string, disablePrint bool, vars map[string][]byte, log *
 	isCrashed := false
 	var globalErr error
 	var wg sync.WaitGroup
-	wg.Go(func() {
-		defer cfgFileRBuf.Close()
-		defer resPrintBuf.Close()
-
-		for {
-			cfg := parserRBuf.Read()
-			if cfg == nil {
-				break
+	if opts.Parallel > 1 {
+		wg.Go(func() {
+			defer cfgFileRBuf.Close()
+			defer resPrintBuf.Close()
+
+			p := parallel{
+				cPath: cPath, sData: &sData, opts: opts,
+				vars: vars, defs: defs, ses: ses, rep: rep, trnsp: trnsp,
+				toFile: cfgFileRBuf, toPrint: resPrintBuf, log: log,
 			}
+			isCrashed, globalErr = p.run(parserRBuf)
+			resHub = p.resHub
+		})
+	} else {
+		wg.Go(func() {
+			defer cfgFileRBuf.Close()
+			defer resPrintBuf.Close()
 
+			// jumpTo is a target of jump, it isn't executed twice as teardown
+			jumpTo := parser.Error
+			// configs before skipTo are skipped by 'fail=skip:<id>' of skipFrom
+			skipTo, skipFrom := parser.Error, parser.Error
 			for {
-				cfgToFile := cfg.Clone()
-				log.Debug("processing config",
-					zap.String("op", op),
-					zap.String("name", cfg.GetName()),
-					zap.Int("id", cfg.GetID()))
-
-				applyDeps(cfg, &resHub, vars, log)
-
-				res := transportRBuf.Read()
-				execCfg := cfg.UnwrapExec()
-
-				if ok := applyVars(cfg, vars, log); !ok {
-					break
-				}
-
-				if ok := applyEnvs(cfg, log); !ok {
+				cfg := parserRBuf.Read()
+				if cfg == nil {
 					break
 				}
 
-				applyCerts(cfg, execCfg, log)
-
-				applyWait(cfg, execCfg, log)
-
-				if t := cfg.GetTimeout(); t != nil {
-					execCfg.SetTimeout(t)
+				// after crash or jump only teardown configs are executed
+				if isCrashed {
+					if !isAlways(cfg) || cfg.GetID() == jumpTo {
+						cfg.Release()
+						continue
+					}
+					log.Debug("teardown config",
+						zap.String("op", op),
+						zap.String("name", cfg.GetName()),
+						zap.Int("id", cfg.GetID()))
+				} else if cfg.GetID() < skipTo && !isAlways(cfg) {
+					// config stays in file as is
+					resPrintBuf.Write(&transport.Result{CfgID: cfg.GetID(), Skipped: true})
+					rep.add(skipCase(cPath, cfg, fmt.Sprintf("skipped by config %d", skipFrom), nil))
+					cfgFileRBuf.Write(cfg.Clone())
+					cfg.Release()
+					continue
 				}
 
-				if impCfg, ok := cfg.(*config.ImportConfig); ok {
-					log.Debug("import config",
+				for {
+					cfgToFile := cfg.Clone()
+					log.Debug("processing config",
 						zap.String("op", op),
 						zap.String("name", cfg.GetName()),
 						zap.Int("id", cfg.GetID()))
 
-					if err := handleConfig(impCfg.TargetPath, disablePrint, vars, log); err != nil {
-						log.Error("Failed to handle config",
+					res := transportRBuf.Read()
+					execCfg := cfg.UnwrapExec()
+					var sendErr error
+					var rows []rowRun
+
+					state, ifErr := prepareConfig(cfg, execCfg, &resHub, vars, log)
+					if state == prepBroken {
+						break
+					}
+					if state == prepSkipped {
+						res.CfgID = cfg.GetID()
+						res.Skipped = true
+						resPrintBuf.Write(res)
+						rep.add(skipCase(cPath, cfg, "condition is false", ifErr))
+
+						// config stays in file with old response
+						if !isCrashed {
+							cfgFileRBuf.Write(cfgToFile)
+						}
+
+						cfg.Release()
+						// res is kept by printer, so put a new one
+						transportRBuf.Write(new(transport.Result))
+						break
+					}
+
+					if impCfg, ok := cfg.(*config.ImportConfig); ok {
+						log.Debug("import config",
 							zap.String("op", op),
 							zap.String("name", cfg.GetName()),
-							zap.Int("id", cfg.GetID()),
-							zap.Error(err))
-						globalErr = err
-						isCrashed = true // for 'copyTail'
-						return
+							zap.Int("id", cfg.GetID()))
+
+						imported, err := handleConfig(impCfg.TargetPath, opts, vars, defs, ses, rep, log)
+						if err != nil {
+							log.Error("Failed to handle config",
+								zap.String("op", op),
+								zap.String("name", cfg.GetName()),
+								zap.Int("id", cfg.GetID()),
+								zap.Error(err))
+							globalErr = err
+							isCrashed = true // for 'copyTail'
+							cfg.Release()
+							transportRBuf.Write(res)
+							break
+						}
+						res.Info.Code = importConfigCode
+						res.Imported = imported
+					} else if len(execCfg.GetForEach()) != 0 {
+						rows, sendErr = sendEach(cPath, cfg, execCfg, cfgToFile, &resHub, vars, trnsp, res, disablePrint, log)
+					} else if len(execCfg.GetDataFile()) != 0 {
+						rows, sendErr = sendRows(cPath, cfg, execCfg, cfgToFile, &resHub, vars, trnsp, res, disablePrint, log)
+					} else {
+						sendErr = sendUntil(cfg, execCfg, cfgToFile, &resHub, vars, nil, false, trnsp, res, disablePrint, log)
+						if sendErr == nil {
+							applyCapture(cfg, execCfg, res, vars, log)
+						}
 					}
-					res.Info.Code = importConfigCode
-				} else {
-					sendConfig(cfg, execCfg, trnsp, res, disablePrint, log)
-				}
 
-				res.CfgID = cfg.GetID()
+					res.CfgID = cfg.GetID()
 
-				resHub = append(resHub, res)
+					resHub[res.CfgID] = res
 
-				resPrintBuf.Write(res)
+					var id int
+					var fail string
+					if rows != nil {
+						for _, r := range rows {
+							resPrintBuf.Write(r.res)
+							rep.add(r.c)
+						}
+						id, fail = rowsOutcome(rows)
+					} else {
+						resPrintBuf.Write(res)
 
-				id := applyExpect(cfg, execCfg, res, log)
+						id, fail = applyExpect(cfg, execCfg, res, log)
+						if res.Info.Code != importConfigCode {
+							rep.add(newCase(cPath, cfg, res, id, fail, sendErr))
+						}
+					}
 
-				if !isCrashed {
-					cfgToFile.Update(res.Raw, res.Cookie)
-					cfgFileRBuf.Write(cfgToFile)
-				}
+					if id == parser.ExpectSkip {
+						if !isCrashed {
+							skipTo, skipFrom = skipTarget(cfg, execCfg), cfg.GetID()
+						}
+						id = parser.ExpectDone
+					}
 
-				if id == parser.ExpectDone && !isCrashed {
-					cfg.Release()
-					break
-				} else if id == parser.ExpectDone && isCrashed {
-					cfg.Release()
-					transportRBuf.Write(res)
-					return
-				}
+					if !isCrashed {
+						cfgToFile.Update(fileResponse(res), res.Cookie)
+						cfgFileRBuf.Write(cfgToFile)
+					}
 
-				if id == parser.ExpectCrash {
-					cfg.Release()
-					transportRBuf.Write(res)
-					isCrashed = true
-					return
-				}
+					// res is kept in resHub, so put a new one
+					if id == parser.ExpectDone || isCrashed {
+						cfg.Release()
+						transportRBuf.Write(new(transport.Result))
+						break
+					}
 
-				if isCrashed {
-					cfg.Release()
-					transportRBuf.Write(res)
-					return
-				}
+					if id == parser.ExpectCrash {
+						cfg.Release()
+						transportRBuf.Write(new(transport.Result))
+						isCrashed = true
+						break
+					}
+
+					isCrashed = true
+					jumpTo = id
+					var nextCfg config.Config
+					origEnd := cfg.GetEnd()
 
-				isCrashed = true
-				var nextCfg config.Config
-				origEnd := cfg.GetEnd()
+					if err := parser.ParseFindConfig(&sData, defs, &nextCfg, id); err != nil {
+						log.Error("Failed to find config",
+							zap.String("op", op),
+							zap.String("name", cfg.GetName()),
+							zap.Int("id", cfg.GetID()),
+							zap.Int("target", id),
+							zap.Error(err))
+						cfg.Release()
+						transportRBuf.Write(new(transport.Result))
+						break
+					}
 
-				if err := parser.ParseFindConfig(&sData, &nextCfg, id); err != nil {
-					log.Error("Failed to find config",
-						zap.String("op", op),
-						zap.String("name", cfg.GetName()),
-						zap.Int("id", cfg.GetID()),
-						zap.Int("target", id),
-						zap.Error(err))
 					cfg.Release()
-					transportRBuf.Write(res)
-					break
+					transportRBuf.Write(new(transport.Result))
+					cfg = nextCfg
+					cfg.SetEnd(origEnd)
 				}
-
-				cfg.Release()
-				transportRBuf.Write(res)
-				cfg = nextCfg
-				cfg.SetEnd(origEnd)
 			}
-		}
-	})
+		})
+	}
 
-	if !soloCfg {
+	if !noWrite {
 		wg.Go(func() {
 			cnt, bufSize := 0, 5
 			var buf bytes.Buffer
 			var pendingOffset int64
 			var cfg config.Config
 
+			// defaults block is kept at start of file
+			if defs != nil {
+				buf.Write(defs.Block)
+				pendingOffset = int64(len(defs.Block))
+			}
+
 			tmpPath := cPath + ".out.tmp"
 			f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
 			if err != nil {
@@ -276,6 +537,7 @@ func handleConfig(cPath string, disablePrint bool, vars map[string][]byte, log *
 					return
 				}
 
+				cfg.StripDefaults()
 				data, err := gurlf.Marshal(cfg)
 				if err != nil {
 					cfg.ReleaseClone()
@@ -326,7 +588,7 @@ func handleConfig(cPath string, disablePrint bool, vars map[string][]byte, log *
 					break
 				}
 
-				if err := prettyPrint(res); err != nil {
+				if err := prettyPrint(res, opts.Verbose); err != nil {
 					log.Error("Failed to print response",
 						zap.String("op", op),
 						zap.Error(err))
@@ -335,21 +597,132 @@ func handleConfig(cPath string, disablePrint bool, vars map[string][]byte, log *
 		})
 	}
 
-	if err := parser.ParseStream(&sData, parserRBuf.Write, log); err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+	if err := parser.ParseStream(&sData, defs, parserRBuf.Write, log); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	parserRBuf.Close()
 
 	wg.Wait()
 
 	if globalErr != nil {
-		return fmt.Errorf("%s: %w", op, globalErr)
+		return nil, fmt.Errorf("%s: %w", op, globalErr)
+	}
+	return &transport.Results{Hub: resHub, Names: parser.ConfigNames(&sData)}, nil
+}
+
+// States of prepared config.
+const (
+	// prepReady for config which is ready to be sent.
+	prepReady = iota
+
+	// prepBroken for config which can't be prepared.
+	prepBroken
+
+	// prepSkipped for config with false or invalid 'If' condition.
+	prepSkipped
+)
+
+// prepareConfig applies dependencies, variables, environments and settings to config.
+// Condition is checked after dependencies, so skipped config doesn't set variables or wait.
+// Returns state of config like prepReady and error of invalid condition.
+func prepareConfig(cfg, execCfg config.Config, resHub *[]*transport.Result, vars map[string][]byte, log *zap.Logger) (int, error) {
+	applyDeps(cfg, resHub, vars, nil, false, log)
+
+	if run, err := applyIf(cfg, log); !run {
+		return prepSkipped, err
+	}
+
+	if ok := applyVars(cfg, vars, log); !ok {
+		return prepBroken, nil
+	}
+
+	if ok := applyEnvs(cfg, log); !ok {
+		return prepBroken, nil
+	}
+
+	applySettings(cfg, execCfg, log)
+
+	applyWait(cfg, execCfg, log)
+
+	return prepReady, nil
+}
+
+// applyIf checks expanded 'If' field of config.
+// Config without condition is always executed.
+// Returns false if config must be skipped and error of invalid condition.
+func applyIf(cfg config.Config, log *zap.Logger) (bool, error) {
+	const op = "core.applyIf"
+
+	cond := cfg.GetIf()
+	if len(cond) == 0 {
+		return true, nil
+	}
+
+	run, err := parser.ParseIf(cond)
+	if err != nil {
+		log.Error("Failed to check condition, config is skipped",
+			zap.String("op", op),
+			zap.String("name", cfg.GetName()),
+			zap.Int("id", cfg.GetID()),
+			zap.Error(err))
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checked condition",
+		zap.String("op", op),
+		zap.String("name", cfg.GetName()),
+		zap.Int("id", cfg.GetID()),
+		zap.String("if", unsafe.String(unsafe.SliceData(cond), len(cond))),
+		zap.Bool("run", run))
+
+	return run, nil
+}
+
+// isAlways reports whether config is teardown.
+// Teardown config has true 'Always' field or '[finally]' name.
+// It is executed even after crash or jump.
+func isAlways(cfg config.Config) bool {
+	return parser.IsTrue(cfg.GetAlways()) || cfg.GetName() == "finally"
+}
+
+// applySettings passes request settings of config to execCfg.
+// So child 'repeat' configs override settings of target.
+func applySettings(cfg, execCfg config.Config, log *zap.Logger) {
+	applyCerts(cfg, execCfg, log)
+
+	if t := cfg.GetTimeout(); t != nil {
+		execCfg.SetTimeout(t)
+	}
+
+	if r := cfg.GetRetry(); r != nil {
+		execCfg.SetRetry(r)
+	}
+
+	if u := cfg.GetUntil(); u != nil {
+		execCfg.SetUntil(u)
+	}
+
+	if i := cfg.GetInterval(); i != nil {
+		execCfg.SetInterval(i)
+	}
+
+	if m := cfg.GetMaxWait(); m != nil {
+		execCfg.SetMaxWait(m)
+	}
+
+	if f := cfg.GetDataFile(); f != nil {
+		execCfg.SetDataFile(f)
+	}
+
+	if f := cfg.GetForEach(); f != nil {
+		execCfg.SetForEach(f)
 	}
-	return nil
 }
 
 // applyDeps applied dependencies for config.
-func applyDeps(cfg config.Config, resHub *[]*transport.Result, vars map[string][]byte, log *zap.Logger) {
+// row is a row of 'DataFile', nil if config is not executed by rows.
+// each reports whether config is executed for element of 'ForEach'.
+func applyDeps(cfg config.Config, resHub *[]*transport.Result, vars map[string][]byte, row map[string][]byte, each bool, log *zap.Logger) {
 	const op = "core.applyDeps"
 
 	allDeps := make([]config.Dependency, 0, cfg.GetDepsLen())
@@ -399,6 +772,11 @@ func applyDeps(cfg config.Config, resHub *[]*transport.Result, vars map[string][
 
 			continue
 		case config.DataFromVariable:
+			if !each && d.Key != "ForEach" && hasForEach(cfg) {
+				// expanded for each element of 'ForEach'
+				continue
+			}
+
 			rawSnapshot := make([]byte, len(cfg.GetRaw(d.Key)))
 			copy(rawSnapshot, cfg.GetRaw(d.Key))
 
@@ -443,6 +821,48 @@ func applyDeps(cfg config.Config, resHub *[]*transport.Result, vars map[string][
 				zap.String("key", d.Key),
 				zap.String("val", unsafe.String(unsafe.SliceData(val), len(val))))
 
+			continue
+		case config.DataFromRow:
+			if row == nil {
+				// expanded for each row of 'DataFile'
+				continue
+			}
+
+			var instructionBytes []byte
+			if !getInstructionBytes(cfg, d, &instructionBytes, log) {
+				continue
+			}
+
+			var key, val []byte
+			parser.GetVarKey(instructionBytes, &key, &val)
+			if key == nil {
+				log.Error("Failed to get row key",
+					zap.String("op", op),
+					zap.String("key", d.Key),
+					zap.String("inst", string(instructionBytes)))
+				continue
+			}
+
+			keyStr := unsafe.String(unsafe.SliceData(key), len(key))
+			if rowVal, ok := row[keyStr]; ok {
+				val = rowVal
+			} else if val == nil {
+				log.Error("No column in row",
+					zap.String("op", op),
+					zap.String("name", cfg.GetName()),
+					zap.String("key", keyStr))
+				continue
+			}
+
+			cfg.Apply(d.Start, d.End, d.Key, val)
+
+			log.Debug("apply row",
+				zap.String("op", op),
+				zap.String("name", cfg.GetName()),
+				zap.Int("id", cfg.GetID()),
+				zap.String("key", d.Key),
+				zap.String("val", unsafe.String(unsafe.SliceData(val), len(val))))
+
 			continue
 		case config.DataFromEnvironment:
 			rawSnapshot := make([]byte, len(cfg.GetRaw(d.Key)))
@@ -566,19 +986,25 @@ func applyDeps(cfg config.Config, resHub *[]*transport.Result, vars map[string][
 			continue
 		}
 
+		if d.Inner != "" {
+			if resp = importedResult(resp, d.Inner, log); resp == nil {
+				continue
+			}
+		}
+
 		log.Debug("Dependency",
 			zap.String("op", op),
 			zap.Int("TargetID for resp", d.TargetID),
 			zap.String("key", d.Key),
 			zap.String("name", cfg.GetName()))
 
-		val := bind.From(resp)
-
 		var instructionBytes []byte
 		if !getInstructionBytes(cfg, d, &instructionBytes, log) {
 			continue
 		}
 
+		val := bind.From(resp, instructionBytes)
+
 		bind.To(cfg, d.Start, d.End, d.Key, val, instructionBytes)
 
 		log.Debug("applied dependencies",
@@ -590,6 +1016,119 @@ func applyDeps(cfg config.Config, resHub *[]*transport.Result, vars map[string][
 	}
 }
 
+// importedResult returns result of config inside imported file.
+// inner is a name or id of config, like 'login' or 'auth.login' for nested import.
+// Returns nil if result is missing.
+func importedResult(res *transport.Result, inner string, log *zap.Logger) *transport.Result {
+	const op = "core.importedResult"
+
+	for inner != "" {
+		var seg string
+		seg, inner, _ = strings.Cut(inner, ".")
+
+		if res.Imported == nil {
+			log.Error("Dependency points to config of not imported file",
+				zap.String("op", op),
+				zap.Int("config id", res.CfgID),
+				zap.String("inner", seg))
+			return nil
+		}
+
+		id, err := strconv.Atoi(seg)
+		if err != nil {
+			var ok bool
+			if id, ok = res.Imported.Names[seg]; !ok || id == parser.Error {
+				log.Error("Dependency points to missing or ambiguous config name",
+					zap.String("op", op),
+					zap.Int("config id", res.CfgID),
+					zap.String("inner", seg))
+				return nil
+			}
+		}
+
+		if id < 0 || id >= len(res.Imported.Hub) || res.Imported.Hub[id] == nil {
+			log.Warn("Response for dependency is empty",
+				zap.String("op", op),
+				zap.Int("config id", res.CfgID),
+				zap.String("inner", seg))
+			return nil
+		}
+		res = res.Imported.Hub[id]
+	}
+
+	return res
+}
+
+// resultValue returns value of result by kind and argument.
+// Kinds are parser.RespBody, parser.RespJSON and others.
+// Returns false if value is missing.
+func resultValue(res *transport.Result, kind int, arg []byte) ([]byte, bool) {
+	switch kind {
+	case parser.RespJSON:
+		return parser.JSONPath(res.Raw, arg)
+	case parser.RespHeader:
+		val := headerValue(res.Header, arg)
+		return val, val != nil
+	case parser.RespTrailer:
+		val := headerValue(res.Trailer, arg)
+		return val, val != nil
+	case parser.RespStatus:
+		return strconv.AppendInt(nil, int64(res.Info.Code), 10), true
+	case parser.RespDuration:
+		return strconv.AppendInt(nil, res.Duration.Milliseconds(), 10), true
+	case parser.RespSize:
+		return strconv.AppendInt(nil, int64(res.Size), 10), true
+	case parser.RespProto:
+		return []byte(res.Proto), res.Proto != ""
+	}
+
+	return res.Raw, res.Raw != nil
+}
+
+// fileResponse returns response written back to file.
+// If request was re-sent by 'Retry', count of attempts is put before it, like '[attempts 3]'.
+func fileResponse(res *transport.Result) []byte {
+	if res.Attempts <= 1 {
+		return res.Raw
+	}
+
+	buf := fmt.Appendf(nil, "[attempts %d]\n", res.Attempts)
+	return append(buf, res.Raw...)
+}
+
+// storedResponse returns response and count of attempts from 'Response' field of file.
+// It is inverse of fileResponse. Count is 1 if response has no '[attempts N]' line.
+func storedResponse(raw []byte) ([]byte, int) {
+	rest, ok := bytes.CutPrefix(raw, []byte("[attempts "))
+	if !ok {
+		return raw, 1
+	}
+
+	num, body, ok := bytes.Cut(rest, []byte("]\n"))
+	if !ok {
+		return raw, 1
+	}
+	n, err := strconv.Atoi(string(num))
+	if err != nil || n <= 1 {
+		return raw, 1
+	}
+	return body, n
+}
+
+// headerValue returns all values of header joined by comma.
+func headerValue(h http.Header, name []byte) []byte {
+	if h == nil || len(name) == 0 {
+		return nil
+	}
+
+	vals := h.Values(string(name))
+	if len(vals) == 0 {
+		return nil
+	}
+
+	return []byte(strings.Join(vals, ", "))
+}
+
 // applyVars applied vars for config.
 func applyVars(cfg config.Config, vars map[string][]byte, log *zap.Logger) bool {
 	const op = "core.applyVars"
@@ -627,7 +1166,14 @@ func applyEnvs(cfg config.Config, log *zap.Logger) bool {
 			zap.Error(err))
 		return false
 	}
-	parser.ApplyEnvs(gscanEnvs)
+	if err := parser.ApplyEnvs(gscanEnvs); err != nil {
+		log.Error("Failed to apply envs",
+			zap.String("op", op),
+			zap.String("name", cfg.GetName()),
+			zap.Int("id", cfg.GetID()),
+			zap.Error(err))
+		return false
+	}
 
 	log.Debug("set environments",
 		zap.String("op", op),
@@ -683,7 +1229,8 @@ func applyWait(cfg config.Config, execCfg config.Config, log *zap.Logger) {
 // execCfg is config for execution.
 // cfg is config for debug.
 // dp is disable print flag.
-func sendConfig(cfg config.Config, execCfg config.Config, trnsp *transport.Transport, res *transport.Result, dp bool, log *zap.Logger) {
+// Returns error of transport.
+func sendConfig(cfg config.Config, execCfg config.Config, trnsp *transport.Transport, res *transport.Result, dp bool, log *zap.Logger) error {
 	const op = "core.sendConfig"
 
 	var err error
@@ -718,25 +1265,287 @@ func sendConfig(cfg config.Config, execCfg config.Config, trnsp *transport.Trans
 			zap.String("config type", cfg.GetType()),
 			zap.Error(err))
 	}
+	return err
+}
+
+// sendUntil sends config and re-sends it until 'Until' condition holds.
+// Before each re-send instructions are expanded again on a copy of tmpl.
+// row is a row of 'DataFile' for expansion, can be nil.
+// each reports whether config is executed for element of 'ForEach'.
+// Polling stops after 'MaxWait', last response is kept.
+// Returns error of last send.
+func sendUntil(cfg, execCfg, tmpl config.Config, resHub *[]*transport.Result, vars, row map[string][]byte, each bool, trnsp *transport.Transport, res *transport.Result, dp bool, log *zap.Logger) error {
+	const op = "core.sendUntil"
+
+	until := execCfg.GetUntil()
+	if len(until) == 0 || tmpl == nil {
+		return sendWithRetry(cfg, execCfg, trnsp, res, dp, log)
+	}
+
+	interval := pollDuration(cfg, execCfg.GetInterval(), untilInterval, log)
+	maxWait := pollDuration(cfg, execCfg.GetMaxWait(), untilMaxWait, log)
+	deadline := time.Now().Add(maxWait)
+
+	for n := 1; ; n++ {
+		err := sendWithRetry(cfg, execCfg, trnsp, res, dp, log)
+
+		ok, cErr := untilMatch(until, res)
+		if cErr != nil {
+			log.Error("Failed to parse until",
+				zap.String("op", op),
+				zap.String("config name", cfg.GetName()),
+				zap.Int("config id", cfg.GetID()),
+				zap.Error(cErr))
+			return err
+		}
+		if ok {
+			log.Debug("Until condition holds",
+				zap.String("op", op),
+				zap.String("config name", cfg.GetName()),
+				zap.Int("config id", cfg.GetID()),
+				zap.Int("polls", n))
+			return err
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			log.Warn("Until condition not met before max wait",
+				zap.String("op", op),
+				zap.String("config name", cfg.GetName()),
+				zap.Int("config id", cfg.GetID()),
+				zap.Int("polls", n),
+				zap.Duration("max wait", maxWait))
+			return err
+		}
+
+		log.Debug("poll",
+			zap.String("op", op),
+			zap.String("config name", cfg.GetName()),
+			zap.Int("config id", cfg.GetID()),
+			zap.Int("poll", n),
+			zap.Int("response code", res.Info.Code),
+			zap.Duration("interval", interval))
+		time.Sleep(interval)
+
+		cfg = allocConfig(tmpl)
+		execCfg = cfg.UnwrapExec()
+		applyDeps(cfg, resHub, vars, row, each, log)
+		applySettings(cfg, execCfg, log)
+		*res = transport.Result{}
+	}
+}
+
+// pollDuration parses 'Interval' or 'MaxWait' field.
+// Returns def if field is empty or invalid.
+func pollDuration(cfg config.Config, field []byte, def time.Duration, log *zap.Logger) time.Duration {
+	const op = "core.pollDuration"
+
+	d := parser.ParseWait(field)
+	if d == parser.Error {
+		log.Error("Failed to parse poll duration",
+			zap.String("op", op),
+			zap.String("config name", cfg.GetName()),
+			zap.String("value", unsafe.String(unsafe.SliceData(field), len(field))))
+	}
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+// untilMatch reports whether all conditions of 'Until' hold for response.
+// Conditions have same syntax as 'Assert'.
+func untilMatch(until []byte, res *transport.Result) (bool, error) {
+	ok := true
+	err := parser.ParseAssert(until, func(a parser.Assertion) {
+		got, found := resultValue(res, a.Kind, a.Arg)
+		if !parser.CheckAssert(a, got, found) {
+			ok = false
+		}
+	})
+	return ok, err
+}
+
+// sendWithRetry sends config and re-sends it by 'Retry' policy.
+// Without 'Retry' field 'fail=retry(n)' action of 'Expect' is used.
+// Between attempts it sleeps by policy backoff.
+// Count of sends is stored in result, if config has policy.
+// Returns error of last attempt.
+func sendWithRetry(cfg config.Config, execCfg config.Config, trnsp *transport.Transport, res *transport.Result, dp bool, log *zap.Logger) error {
+	const op = "core.sendWithRetry"
+
+	var p parser.RetryPolicy
+	if retry := execCfg.GetRetry(); len(retry) != 0 {
+		var err error
+		if p, err = parser.ParseRetry(retry); err != nil {
+			log.Error("Failed to parse retry",
+				zap.String("op", op),
+				zap.String("config name", cfg.GetName()),
+				zap.Int("config id", cfg.GetID()),
+				zap.Error(err))
+			p = parser.RetryPolicy{}
+		}
+	} else if n, ok := failRetries(cfg, execCfg); ok {
+		p = parser.FailRetryPolicy(n)
+	} else {
+		return sendConfig(cfg, execCfg, trnsp, res, dp, log)
+	}
+
+	var err error
+	for n := 0; ; n++ {
+		*res = transport.Result{}
+		err = sendConfig(cfg, execCfg, trnsp, res, dp, log)
+		res.Attempts = n + 1
+
+		if n == p.Retries {
+			return err
+		}
+
+		var netErr net.Error
+		isTimeout := errors.Is(err, context.DeadlineExceeded) ||
+			errors.As(err, &netErr) && netErr.Timeout()
+		isGRPC := res.Info.ConfigType == "grpc"
+
+		if !parser.RetryMatch(p, res.Info.Code, isGRPC, err != nil, isTimeout,
+			expectFailed(cfg, execCfg, res)) {
+			return err
+		}
+
+		delay := parser.RetryDelay(p, n+1)
+		log.Warn("Retry config",
+			zap.String("op", op),
+			zap.String("config name", cfg.GetName()),
+			zap.Int("config id", cfg.GetID()),
+			zap.Int("attempt", n+1),
+			zap.Int("response code", res.Info.Code),
+			zap.Duration("delay", delay),
+			zap.Error(err))
+		time.Sleep(delay)
+	}
+}
+
+// failRetries returns count of retries by 'fail=retry(n)' action of 'Expect'.
+// Returns false if config has other action.
+func failRetries(cfg config.Config, execCfg config.Config) (int, bool) {
+	action, n := parser.ParseFail(expectField(cfg, execCfg))
+	return n, action == parser.ExpectRetry
+}
+
+// skipTarget returns target id of 'fail=skip:<id>' action of 'Expect'.
+func skipTarget(cfg config.Config, execCfg config.Config) int {
+	_, to := parser.ParseFail(expectField(cfg, execCfg))
+	return to
+}
+
+// expectField returns 'Expect' of config or of its exec config.
+func expectField(cfg config.Config, execCfg config.Config) []byte {
+	if expect := cfg.GetExpect(); expect != nil {
+		return expect
+	}
+	return execCfg.GetExpect()
+}
+
+// expectFailed reports whether response fails 'Expect' or 'Assert'.
+// Unlike applyExpect, it doesn't log and doesn't change config.
+func expectFailed(cfg config.Config, execCfg config.Config, res *transport.Result) bool {
+	expect := cfg.GetExpect()
+	if expect == nil {
+		expect = execCfg.GetExpect()
+	}
+	if id := parser.ParseExpect(expect, res.Info.Code); id != parser.ExpectDone && id != parser.Error {
+		return true
+	}
+
+	assert := cfg.GetAssert()
+	if assert == nil {
+		assert = execCfg.GetAssert()
+	}
+	if len(assert) == 0 {
+		return false
+	}
+
+	failed := false
+	if err := parser.ParseAssert(assert, func(a parser.Assertion) {
+		got, found := resultValue(res, a.Kind, a.Arg)
+		if !parser.CheckAssert(a, got, found) {
+			failed = true
+		}
+	}); err != nil {
+		return true
+	}
+	return failed
+}
+
+// applyCapture stores values of response into vars by capture field.
+// Values which are missing in response are skipped.
+func applyCapture(cfg, execCfg config.Config, res *transport.Result, vars map[string][]byte, log *zap.Logger) {
+	const op = "core.applyCapture"
+
+	if cfg.GetCapture() == nil && execCfg.GetCapture() != nil {
+		cfg.SetCapture(execCfg.GetCapture())
+	}
+
+	if len(cfg.GetCapture()) == 0 {
+		return
+	}
+
+	if err := parser.ParseCapture(cfg.GetCapture(), func(name []byte, kind int, arg []byte) {
+		val, ok := resultValue(res, kind, arg)
+		if !ok {
+			log.Warn("No value for capture",
+				zap.String("op", op),
+				zap.String("config name", cfg.GetName()),
+				zap.Int("config id", cfg.GetID()),
+				zap.ByteString("name", name),
+				zap.ByteString("arg", arg))
+			return
+		}
+
+		vars[string(name)] = bytes.Clone(val)
+
+		log.Debug("capture",
+			zap.String("op", op),
+			zap.String("config name", cfg.GetName()),
+			zap.Int("config id", cfg.GetID()),
+			zap.ByteString("name", name),
+			zap.ByteString("val", val))
+	}); err != nil {
+		log.Error("Failed to parse capture",
+			zap.String("op", op),
+			zap.String("config name", cfg.GetName()),
+			zap.Int("config id", cfg.GetID()),
+			zap.Error(err))
+	}
 }
 
 // applyExpect parse expect field and return id.
 // ID is special value from parser or target id for jump to config.
-func applyExpect(cfg config.Config, execCfg config.Config, res *transport.Result, log *zap.Logger) int {
+// Also returns failure message, it is empty if expectations passed.
+func applyExpect(cfg config.Config, execCfg config.Config, res *transport.Result, log *zap.Logger) (int, string) {
 	const op = "core.applyExpect"
 
 	if cfg.GetExpect() == nil && execCfg.GetExpect() != nil {
 		cfg.SetExpect(execCfg.GetExpect())
 	}
 
-	if id := parser.ParseExpect(cfg.GetExpect(), res.Info.Code); id == parser.Error {
+	var fail string
+	id := parser.ParseExpect(cfg.GetExpect(), res.Info.Code)
+	if id != parser.ExpectDone && id != parser.Error {
+		fail = fmt.Sprintf("expected %s, but got %d", cfg.GetExpect(), res.Info.Code)
+	} else if id == parser.ExpectDone {
+		if fails := applyAssert(cfg, execCfg, res, log); len(fails) != 0 {
+			id = parser.ParseFailAction(cfg.GetExpect())
+			fail = strings.Join(fails, "; ")
+		}
+	}
+
+	if id == parser.Error {
 		expStr := unsafe.String(unsafe.SliceData(cfg.GetExpect()), len(cfg.GetExpect()))
 		log.Error("Failed to parse expect",
 			zap.String("op", op),
 			zap.String("config name", cfg.GetName()),
 			zap.Int("config id", cfg.GetID()),
 			zap.String("expected", expStr))
-		return parser.ExpectDone
+		return parser.ExpectDone, fail
 	} else if id != parser.ExpectDone {
 		expStr := unsafe.String(unsafe.SliceData(cfg.GetExpect()), len(cfg.GetExpect()))
 		log.Error("Expected fail",
@@ -751,21 +1560,80 @@ func applyExpect(cfg config.Config, execCfg config.Config, res *transport.Result
 			log.Debug("Expected action",
 				zap.String("op", op),
 				zap.String("action", "crash"))
-			return parser.ExpectCrash
+			return parser.ExpectCrash, fail
+		} else if id == parser.ExpectSkip {
+			if to := skipTarget(cfg, execCfg); to > cfg.GetID() {
+				log.Debug("Expected action",
+					zap.String("op", op),
+					zap.Int("action: skip to id", to))
+				return parser.ExpectSkip, fail
+			}
+			log.Error("Skip target must be after config, action is ignored",
+				zap.String("op", op),
+				zap.String("config name", cfg.GetName()),
+				zap.Int("config id", cfg.GetID()),
+				zap.String("expected", expStr))
+			return parser.ExpectDone, fail
 		} else if id < 0 {
 			log.Debug("Expected action",
 				zap.String("op", op),
 				zap.String("action", "ignore"))
-			return parser.ExpectDone
+			return parser.ExpectDone, fail
 		}
 
 		log.Debug("Expected action",
 			zap.String("op", op),
 			zap.Int("action: goto to id", id))
 
-		return id
+		return id, fail
 	}
-	return parser.ExpectDone
+	return parser.ExpectDone, fail
+}
+
+// applyAssert checks assert field of config.
+// Each failed assertion is reported separately.
+// Returns messages of failed assertions.
+func applyAssert(cfg config.Config, execCfg config.Config, res *transport.Result, log *zap.Logger) []string {
+	const op = "core.applyAssert"
+
+	if cfg.GetAssert() == nil && execCfg.GetAssert() != nil {
+		cfg.SetAssert(execCfg.GetAssert())
+	}
+
+	if len(cfg.GetAssert()) == 0 {
+		return nil
+	}
+
+	var fails []string
+	if err := parser.ParseAssert(cfg.GetAssert(), func(a parser.Assertion) {
+		got, found := resultValue(res, a.Kind, a.Arg)
+		if parser.CheckAssert(a, got, found) {
+			log.Debug("Assertion passed",
+				zap.String("op", op),
+				zap.String("config name", cfg.GetName()),
+				zap.Int("config id", cfg.GetID()),
+				zap.String("assert", unsafe.String(unsafe.SliceData(a.Line), len(a.Line))))
+			return
+		}
+
+		fails = append(fails, fmt.Sprintf("assert %q, but got %q", a.Line, got))
+		log.Error("Assertion failed",
+			zap.String("op", op),
+			zap.String("config name", cfg.GetName()),
+			zap.Int("config id", cfg.GetID()),
+			zap.String("assert", unsafe.String(unsafe.SliceData(a.Line), len(a.Line))),
+			zap.ByteString("got", got),
+			zap.Bool("found", found))
+	}); err != nil {
+		log.Error("Failed to parse assert",
+			zap.String("op", op),
+			zap.String("config name", cfg.GetName()),
+			zap.Int("config id", cfg.GetID()),
+			zap.Error(err))
+		return append(fails, err.Error())
+	}
+
+	return fails
 }
 
 // copyTail copies tail of file to buffer.
@@ -817,7 +1685,8 @@ func flush(buf *bytes.Buffer, f *os.File) error {
 
 // prettyPrint prints response.
 // Ignoring import config.
-func prettyPrint(res *transport.Result) error {
+// If verbose is true, prints TLS handshake too.
+func prettyPrint(res *transport.Result, verbose bool) error {
 	const op = "core.prettyPrint"
 
 	if res.Info.Code == importConfigCode {
@@ -827,6 +1696,21 @@ func prettyPrint(res *transport.Result) error {
 	fmt.Println(strings.Repeat("-", 20))
 
 	fmt.Printf("\n\033[90m[ID %d]\033[0m", res.CfgID)
+	if res.Skipped {
+		fmt.Printf("\n\033[33m[SKIPPED]\033[0m\n")
+		return nil
+	}
+	if res.Duration > 0 {
+		fmt.Printf(" \033[90m[%s]\033[0m", res.Duration.Round(time.Microsecond))
+	}
+	if res.Item != "" {
+		fmt.Printf(" \033[90m[%s]\033[0m", res.Item)
+	} else if res.Row > 0 {
+		fmt.Printf(" \033[90m[row %d]\033[0m", res.Row)
+	}
+	if res.Attempts > 1 {
+		fmt.Printf(" \033[90m[attempts %d]\033[0m", res.Attempts)
+	}
 	switch {
 	case res.Info.Code >= 200 && res.Info.Code < 300:
 		fmt.Printf("\n\033[32m[HTTP %d: %s]\033[0m",
@@ -848,6 +1732,10 @@ func prettyPrint(res *transport.Result) error {
 			res.Info.Code, res.Info.Message)
 	}
 
+	if verbose && res.TLS != nil {
+		printTLS(res.TLS)
+	}
+
 	if len(res.Raw) == 0 {
 		fmt.Printf("\n\033[90m[Empty body]\033[0m")
 		return nil
@@ -870,6 +1758,19 @@ func prettyPrint(res *transport.Result) error {
 	return nil
 }
 
+// printTLS prints details of TLS handshake.
+// Certificates of server are printed from leaf to root.
+func printTLS(info *transport.TLSInfo) {
+	fmt.Printf("\n\033[90m[%s, %s]\033[0m", info.Version, info.Cipher)
+	if info.ServerName != "" {
+		fmt.Printf("\n\033[90m[Server name %s]\033[0m", info.ServerName)
+	}
+	for i, p := range info.Peers {
+		fmt.Printf("\n\033[90m[Cert %d] subject=%q issuer=%q expires=%s\033[0m",
+			i, p.Subject, p.Issuer, p.NotAfter.Format(time.DateOnly))
+	}
+}
+
 // getInstructionBytes get instuction bytes from config.
 // It updated buffer by pointer.
 func getInstructionBytes(cfg config.Config, d config.Dependency, buf *[]byte, log *zap.Logger) bool {
@@ -0,0 +1,259 @@
+package core
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Votline/Gurl-cli/internal/transport"
+)
+
+func TestResultValue(t *testing.T) {
+	tests := []struct {
+		input    string
+		inst     string
+		expected string
+	}{
+		{`"token":   "fjhklghdfsdiuflg"`, `{RESPONSE id=0 json:token}`, `fjhklghdfsdiuflg`},
+		{`"\nToken": "fj\nhklghdfsd\tiuflg\r"`, `{RESPONSE id=15 json:\nToken}`, `fj\nhklghdfsd\tiuflg\r`},
+		{`{"data":{"items":[{"id":15},{"id":16}]}}`, `{RESPONSE id=0 json:data.items[1].id}`, `16`},
+		{`{"data":{"token":"abc"}}`, `{RESPONSE id=0 json:token}`, `abc`},
+		{`{"ok":true}`, `{RESPONSE id=0 json:missing}`, ``},
+		{`plain body`, `{RESPONSE id=0}`, `plain body`},
+		{`plain body`, `{RESPONSE id=0 header:Location}`, `/users/42`},
+		{`plain body`, `{RESPONSE id=0 status}`, `201`},
+	}
+
+	from := depBindings["RESPONSE"].From
+	for i, tt := range tests {
+		res := &transport.Result{
+			Raw:    []byte(tt.input),
+			Header: http.Header{"Location": {"/users/42"}},
+		}
+		res.Info.Code = 201
+
+		val := from(res, []byte(tt.inst))
+		if string(val) != tt.expected {
+			t.Errorf("[%d]: expected %q, but got %q", i, tt.expected, string(val))
+		}
+	}
+}
+
+func BenchmarkResultValue(b *testing.B) {
+	res := &transport.Result{Raw: []byte(`{"data":{"items":[{"id":15},{"id":16}]}}`)}
+	inst := []byte(`{RESPONSE id=0 json:data.items[1].id}`)
+	from := depBindings["RESPONSE"].From
+	for b.Loop() {
+		from(res, inst)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		path     string
+		fields   string
+		expected string
+		sends    int
+		resp     string
+	}{
+		{"/flaky/2", "Retry:3;base=1ms\nExpect:200", "0 a 200 passed", 3, "`[attempts 3]\n{\"state\":\"done\"}`"},
+		{"/flaky/2", "Retry:1;base=1ms\nExpect:200", "0 a 503 failed", 2, "`[attempts 2]\n{\"state\":\"pending\"}`"},
+		{"/flaky/1", "Expect:200;fail=retry(3)", "0 a 200 passed", 2, "`[attempts 2]\n{\"state\":\"done\"}`"},
+		{"/flaky/1", "Expect:200;fail=retry(0)", "0 a 503 failed", 1, `{"state":"pending"}`},
+		{"/flaky/2", "Retry:3;base=1ms;on=429\nExpect:200", "0 a 503 failed", 1, `{"state":"pending"}`},
+		{"/echo", "Retry:3;base=1ms\nExpect:200", "0 a 200 passed", 1, "echo "},
+	}
+
+	for i, tt := range tests {
+		s := newTestServer(t)
+		src := "[a]\nURL:{URL}" + tt.path + "\nID:0\nType:http\n" + tt.fields + "\n[\\a]\n"
+		r := runFile(t, s, src, 0)
+
+		if len(r.cases) != 1 || r.cases[0] != tt.expected {
+			t.Errorf("[%d]: expected %q, but got %q", i, tt.expected, r.cases)
+		}
+		if len(r.paths) != tt.sends {
+			t.Errorf("[%d]: expected %d sends, but got %d", i, tt.sends, len(r.paths))
+		}
+		if !strings.Contains(r.file, "Response:"+tt.resp+"\n") {
+			t.Errorf("[%d]: expected response %q, but got file\n%s", i, tt.resp, r.file)
+		}
+	}
+}
+
+func TestUntil(t *testing.T) {
+	tests := []struct {
+		path     string
+		fields   string
+		expected string
+		minSends int
+		maxSends int
+		resp     string
+	}{
+		{"/flaky/2", "Until:json:state == done\nInterval:5ms\nMaxWait:5s\nExpect:200", "0 a 200 passed", 3, 3, `{"state":"done"}`},
+		{"/flaky/1", "Until:status == 200\nInterval:5ms\nMaxWait:5s", "0 a 200 passed", 2, 2, `{"state":"done"}`},
+		{"/echo", "Until:status == 200\nInterval:5ms\nMaxWait:5s", "0 a 200 passed", 1, 1, "echo "},
+		{"/flaky/1000", "Until:json:state == done\nInterval:20ms\nMaxWait:100ms\nExpect:200", "0 a 503 failed", 2, 6, `{"state":"pending"}`},
+	}
+
+	for i, tt := range tests {
+		s := newTestServer(t)
+		src := "[a]\nURL:{URL}" + tt.path + "\nID:0\nType:http\n" + tt.fields + "\n[\\a]\n"
+
+		start := time.Now()
+		r := runFile(t, s, src, 0)
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("[%d]: expected polling to stop by max wait, but took %v", i, elapsed)
+		}
+
+		if len(r.cases) != 1 || r.cases[0] != tt.expected {
+			t.Errorf("[%d]: expected %q, but got %q", i, tt.expected, r.cases)
+		}
+		if n := len(r.paths); n < tt.minSends || n > tt.maxSends {
+			t.Errorf("[%d]: expected %d-%d sends, but got %d", i, tt.minSends, tt.maxSends, n)
+		}
+		if !strings.Contains(r.file, "Response:"+tt.resp+"\n") {
+			t.Errorf("[%d]: expected response %q, but got file\n%s", i, tt.resp, r.file)
+		}
+	}
+}
+
+func TestDataFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		fields   string
+		expected []string
+		paths    []string
+		resp     string
+	}{
+		{
+			"users.csv",
+			"user,code\nann,200\nbob,404\ncid,201\n",
+			"Expect:2xx",
+			[]string{"0 a[1] 200 passed", "0 a[2] 404 failed", "0 a[3] 201 passed"},
+			[]string{"/status/200", "/status/404", "/status/201"},
+			`{"rows":3,"passed":2,"failed":1,"codes":[200,404,201]}`,
+		},
+		{
+			"users.ndjson",
+			"{\"user\":\"ann\",\"code\":204}\n{\"user\":\"bob\",\"code\":200}\n",
+			"Expect:2xx",
+			[]string{"0 a[1] 204 passed", "0 a[2] 200 passed"},
+			[]string{"/status/204", "/status/200"},
+			`{"rows":2,"passed":2,"failed":0,"codes":[204,200]}`,
+		},
+		{
+			"users.csv",
+			"user,code\nann,200\nbob,500\ncid,200\n",
+			"Expect:200;fail=crash",
+			[]string{"0 a[1] 200 passed", "0 a[2] 500 crashed"},
+			[]string{"/status/200", "/status/500"},
+			`{"rows":2,"passed":1,"failed":1,"codes":[200,500]}`,
+		},
+	}
+
+	for i, tt := range tests {
+		data := filepath.Join(t.TempDir(), tt.name)
+		if err := os.WriteFile(data, []byte(tt.data), 0o644); err != nil {
+			t.Fatalf("write data file: %v", err)
+		}
+
+		s := newTestServer(t)
+		src := "[a]\nURL:{URL}/status/{ROW key=code}\nID:0\nType:http\nDataFile:" + data + "\n" + tt.fields + "\n[\\a]\n"
+		r := runFile(t, s, src, 0)
+
+		if !slices.Equal(r.cases, tt.expected) {
+			t.Errorf("[%d]: expected %q, but got %q", i, tt.expected, r.cases)
+		}
+		if !slices.Equal(r.paths, tt.paths) {
+			t.Errorf("[%d]: expected %q, but got %q", i, tt.paths, r.paths)
+		}
+		if !strings.Contains(r.file, "Response:"+tt.resp+"\n") {
+			t.Errorf("[%d]: expected response %q, but got file\n%s", i, tt.resp, r.file)
+		}
+	}
+}
+
+func TestForEach(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected []string
+		paths    []string
+	}{
+		{
+			"element and index",
+			`[list]
+URL:{URL}/items
+ID:0
+Type:http
+[\list]
+
+[each]
+URL:{URL}/item/{VARIABLE key=ItemID}/{VARIABLE key=Index}
+ID:1
+Type:http
+ForEach:{RESPONSE id=0 json:items[*].id} as ItemID
+[\each]
+
+[after]
+URL:{URL}/after/{VARIABLE key=ItemID ; default=none}/{VARIABLE key=Index ; default=none}
+ID:2
+Type:http
+[\after]
+`,
+			[]string{"0 list 200 passed", "1 each[1] 200 passed", "1 each[2] 200 passed", "1 each[3] 200 passed", "2 after 200 passed"},
+			[]string{"/items", "/item/7/0", "/item/x/1", "/item/9/2", "/after/none/none"},
+		},
+		{
+			"variables restored",
+			`[list]
+URL:{URL}/items
+ID:0
+Type:http
+SetVariables:` + "`" + `
+[vars]
+ItemID: old
+I: 5
+[\vars]
+` + "`" + `
+[\list]
+
+[each]
+URL:{URL}/item/{VARIABLE key=ItemID}/{VARIABLE key=I}
+ID:1
+Type:http
+ForEach:{RESPONSE id=0 json:items[*].id} as ItemID, I
+[\each]
+
+[after]
+URL:{URL}/after/{VARIABLE key=ItemID}/{VARIABLE key=I}
+ID:2
+Type:http
+[\after]
+`,
+			[]string{"0 list 200 passed", "1 each[1] 200 passed", "1 each[2] 200 passed", "1 each[3] 200 passed", "2 after 200 passed"},
+			[]string{"/items", "/item/7/0", "/item/x/1", "/item/9/2", "/after/old/5"},
+		},
+	}
+
+	for i, tt := range tests {
+		s := newTestServer(t)
+		r := runFile(t, s, tt.src, 0)
+
+		if !slices.Equal(r.cases, tt.expected) {
+			t.Errorf("[%d] %s: expected %q, but got %q", i, tt.name, tt.expected, r.cases)
+		}
+		if !slices.Equal(r.paths, tt.paths) {
+			t.Errorf("[%d] %s: expected %q, but got %q", i, tt.name, tt.paths, r.paths)
+		}
+		if !strings.Contains(r.file, `Response:{"rows":3,"passed":3,"failed":0,"codes":[200,200,200]}`+"\n") {
+			t.Errorf("[%d] %s: expected summary in response, but got file\n%s", i, tt.name, r.file)
+		}
+	}
+}
@@ -0,0 +1,652 @@
+// Package core parallel.go contains parallel execution of configs.
+// Configs are executed concurrently by dependency graph.
+// Results are printed, reported and written to file in file order.
+package core
+
+import (
+	"fmt"
+	"slices"
+	"unsafe"
+
+	"github.com/Votline/Gurl-cli/internal/buffer"
+	"github.com/Votline/Gurl-cli/internal/config"
+	"github.com/Votline/Gurl-cli/internal/parser"
+	"github.com/Votline/Gurl-cli/internal/transport"
+
+	gscan "github.com/Votline/Gurlf/pkg/scanner"
+	"go.uber.org/zap"
+)
+
+// States of task.
+const (
+	// taskPending for task which waits for dependencies or free worker.
+	taskPending = iota
+
+	// taskRunning for task which is executing.
+	taskRunning
+
+	// taskDone for executed task.
+	taskDone
+)
+
+// task is a one config in parallel mode.
+type task struct {
+	// cfg is a config for execution.
+	cfg config.Config
+
+	// orig is a config for file, without applied instructions.
+	orig config.Config
+
+	// res is a result of config.
+	res *transport.Result
+
+	// state is a task state. Like taskPending.
+	state int
+
+	// wait is a count of not executed dependencies.
+	wait int
+
+	// next is a ids of configs which depend on task.
+	next []int
+
+	// broken is true if config can't be prepared.
+	broken bool
+
+	// skipped is true if config is skipped by 'If' or 'fail=skip:<id>'.
+	skipped bool
+
+	// skipMsg is a reason of skip.
+	skipMsg string
+
+	// ifErr is a error of invalid 'If' condition.
+	ifErr error
+
+	// expect is a result of applyExpect.
+	expect int
+
+	// fail is a failure message of applyExpect.
+	fail string
+
+	// sendErr is a error of transport.
+	sendErr error
+
+	// impErr is a error of import config.
+	impErr error
+
+	// rows is a runs of rows, if config has 'DataFile'.
+	rows []rowRun
+}
+
+// parallel is a state of parallel execution of one config file.
+type parallel struct {
+	cPath   string
+	sData   *[]gscan.Data
+	opts    Options
+	vars    map[string][]byte
+	defs    *parser.Defaults
+	ses     *transport.Session
+	rep     *Report
+	trnsp   *transport.Transport
+	toFile  buffer.Buffer[config.Config]
+	toPrint buffer.Buffer[*transport.Result]
+	log     *zap.Logger
+
+	// tasks is a list of tasks by config id.
+	tasks []*task
+
+	// resHub is a list of results by config id.
+	resHub []*transport.Result
+
+	// groups maps group name to id of last config in group.
+	groups map[string]int
+
+	// barrier is a id of last config which is executed alone.
+	barrier int
+
+	// ready is a ids of tasks without pending dependencies.
+	ready []int
+
+	// running is a count of executing tasks.
+	running int
+
+	// done receives id of executed task.
+	done chan int
+
+	// commited is a id of next task for commit.
+	commited int
+
+	// stopped is true after crash, jump or import error.
+	// New tasks are not started after stop.
+	stopped bool
+
+	// jump is a target id of first jump. jumpFrom is a id of jumped config.
+	jump, jumpFrom int
+
+	// isCrashed is true if rest of file must be copied as is.
+	isCrashed bool
+
+	// skipTo is a target id of 'fail=skip:<id>' of skipFrom.
+	// Tasks before it are skipped.
+	skipTo, skipFrom int
+
+	// teardown is a not executed teardown tasks.
+	// They are executed one by one after stop.
+	teardown []*task
+
+	// held is a ids of teardown tasks in file order, which are not scheduled.
+	// They are executed one by one when other tasks are executed.
+	held []int
+
+	// err is a first error of import config.
+	err error
+}
+
+// run reads configs from parser and executes them.
+// Count of executing configs is limited by 'Parallel' option.
+// Config starts when all its dependencies are executed:
+// instructions targets, previous config of same group and 'After' list.
+// Teardown configs are executed one by one in file order, like in sequential mode.
+// Returns crash flag for file writer and error of import config.
+func (p *parallel) run(parserRBuf buffer.Buffer[config.Config]) (bool, error) {
+	const op = "core.parallel.run"
+
+	p.resHub = make([]*transport.Result, len(*p.sData))
+	p.groups = make(map[string]int)
+	p.barrier = -1
+	p.jump, p.jumpFrom = -1, -1
+	p.skipTo, p.skipFrom = -1, -1
+	p.done = make(chan int)
+
+	// Configs are moved out of pre-allocated buffers,
+	// because they live until all dependent configs are executed.
+	tasks := make(chan *task)
+	go func() {
+		defer close(tasks)
+		for {
+			cfg := parserRBuf.Read()
+			if cfg == nil {
+				return
+			}
+
+			t := &task{cfg: allocConfig(cfg), orig: allocConfig(cfg)}
+			cfg.Release()
+			tasks <- t
+		}
+	}()
+
+	for {
+		if tasks == nil && p.running == 0 {
+			// only held teardown tasks and tasks which wait for them are left
+			if !p.runHeld() {
+				break
+			}
+		} else {
+			select {
+			case t, ok := <-tasks:
+				if !ok {
+					tasks = nil
+					break
+				}
+				if p.stopped {
+					if isAlways(t.cfg) {
+						p.teardown = append(p.teardown, t)
+					}
+					break
+				}
+				if err := p.add(t); err != nil {
+					p.log.Error("Failed to add config",
+						zap.String("op", op),
+						zap.String("name", t.cfg.GetName()),
+						zap.Int("id", t.cfg.GetID()),
+						zap.Error(err))
+					p.stop(err)
+				}
+			case id := <-p.done:
+				p.finish(id)
+			}
+		}
+
+		p.commit(false)
+		p.schedule()
+	}
+
+	p.commit(true)
+
+	if p.jump != parser.Error {
+		p.runJump()
+	}
+
+	p.runTeardown()
+
+	return p.isCrashed, p.err
+}
+
+// add adds task to graph.
+// Returns error if 'After' points to missing config or group.
+func (p *parallel) add(t *task) error {
+	const op = "core.parallel.add"
+
+	cfg := t.cfg
+	id := cfg.GetID()
+	if id != len(p.tasks) {
+		return fmt.Errorf("%s: unexpected config id %d, want %d", op, id, len(p.tasks))
+	}
+	p.tasks = append(p.tasks, t)
+
+	deps := make([]int, 0, cfg.GetDepsLen()+1)
+	addDep := func(d int) {
+		if d >= 0 && d < id && !slices.Contains(deps, d) {
+			deps = append(deps, d)
+		}
+	}
+
+	if isBarrier(cfg) {
+		for d := range id {
+			addDep(d)
+		}
+		p.barrier = id
+	} else {
+		addDep(p.barrier)
+	}
+
+	cfg.RangeDeps(func(d config.Dependency) {
+		if d.Key != "Response" {
+			addDep(d.TargetID)
+		}
+	})
+
+	var err error
+	parser.ParseAfter(cfg.GetAfter(), func(aID int, group []byte) {
+		if err != nil {
+			return
+		}
+		if group == nil {
+			if aID >= id {
+				err = fmt.Errorf("%s: 'After' id %d is not before config %d", op, aID, id)
+				return
+			}
+			addDep(aID)
+			return
+		}
+
+		last, ok := p.groups[unsafe.String(unsafe.SliceData(group), len(group))]
+		if !ok {
+			err = fmt.Errorf("%s: no configs of group %q before config %d", op, group, id)
+			return
+		}
+		addDep(last)
+	})
+	if err != nil {
+		return err
+	}
+
+	if g := cfg.GetGroup(); len(g) != 0 {
+		if last, ok := p.groups[string(g)]; ok {
+			addDep(last)
+		}
+		p.groups[string(g)] = id
+	}
+
+	for _, d := range deps {
+		dt := p.tasks[d]
+		if dt.state == taskDone {
+			continue
+		}
+		dt.next = append(dt.next, id)
+		t.wait++
+	}
+
+	p.log.Debug("add config",
+		zap.String("op", op),
+		zap.String("name", cfg.GetName()),
+		zap.Int("id", id),
+		zap.Ints("deps", deps),
+		zap.Int("wait", t.wait))
+
+	if isAlways(cfg) {
+		// teardown is executed in file order, after configs before it
+		p.held = append(p.held, id)
+		return nil
+	}
+
+	if id < p.skipTo {
+		p.skipTask(t, p.skipFrom)
+		return nil
+	}
+
+	if t.wait == 0 {
+		p.ready = append(p.ready, id)
+	}
+	return nil
+}
+
+// runHeld executes first held teardown task, when no other task is executing.
+// Its dependent tasks are released like after worker.
+// Returns false if run is stopped or there is no held task.
+func (p *parallel) runHeld() bool {
+	if p.stopped || len(p.held) == 0 {
+		return false
+	}
+
+	t := p.tasks[p.held[0]]
+	if t.wait != 0 {
+		return false
+	}
+	p.held = p.held[1:]
+
+	t.state = taskRunning
+	p.running++
+	p.exec(t)
+	p.finish(t.cfg.GetID())
+	return true
+}
+
+// schedule starts ready tasks while there are free workers.
+func (p *parallel) schedule() {
+	for !p.stopped && p.running < p.opts.Parallel && len(p.ready) > 0 {
+		id := p.ready[0]
+		p.ready = p.ready[1:]
+
+		t := p.tasks[id]
+		t.state = taskRunning
+		p.running++
+
+		go func() {
+			p.exec(t)
+			p.done <- id
+		}()
+	}
+}
+
+// exec executes task. Called from worker goroutine.
+func (p *parallel) exec(t *task) {
+	const op = "core.parallel.exec"
+
+	cfg := t.cfg
+	execCfg := cfg.UnwrapExec()
+	t.res = new(transport.Result)
+
+	p.log.Debug("processing config",
+		zap.String("op", op),
+		zap.String("name", cfg.GetName()),
+		zap.Int("id", cfg.GetID()))
+
+	state, ifErr := prepareConfig(cfg, execCfg, &p.resHub, p.vars, p.log)
+	switch state {
+	case prepBroken:
+		t.broken = true
+		return
+	case prepSkipped:
+		t.skipped, t.skipMsg, t.ifErr = true, "condition is false", ifErr
+		t.res.CfgID = cfg.GetID()
+		t.res.Skipped = true
+		return
+	}
+
+	if impCfg, ok := cfg.(*config.ImportConfig); ok {
+		imported, err := handleConfig(impCfg.TargetPath, p.opts, p.vars, p.defs, p.ses, p.rep, p.log)
+		if err != nil {
+			p.log.Error("Failed to handle config",
+				zap.String("op", op),
+				zap.String("name", cfg.GetName()),
+				zap.Int("id", cfg.GetID()),
+				zap.Error(err))
+			t.impErr = err
+			return
+		}
+		t.res.Info.Code = importConfigCode
+		t.res.Imported = imported
+	} else if len(execCfg.GetForEach()) != 0 {
+		t.rows, t.sendErr = sendEach(p.cPath, cfg, execCfg, t.orig, &p.resHub, p.vars, p.trnsp, t.res, p.opts.DisablePrint, p.log)
+	} else if len(execCfg.GetDataFile()) != 0 {
+		t.rows, t.sendErr = sendRows(p.cPath, cfg, execCfg, t.orig, &p.resHub, p.vars, p.trnsp, t.res, p.opts.DisablePrint, p.log)
+	} else {
+		t.sendErr = sendUntil(cfg, execCfg, t.orig, &p.resHub, p.vars, nil, false, p.trnsp, t.res, p.opts.DisablePrint, p.log)
+		if t.sendErr == nil {
+			applyCapture(cfg, execCfg, t.res, p.vars, p.log)
+		}
+	}
+
+	t.res.CfgID = cfg.GetID()
+	if t.rows != nil {
+		t.expect, t.fail = rowsOutcome(t.rows)
+		return
+	}
+	t.expect, t.fail = applyExpect(cfg, execCfg, t.res, p.log)
+}
+
+// finish marks task as executed and releases dependent tasks.
+func (p *parallel) finish(id int) {
+	t := p.tasks[id]
+	t.state = taskDone
+	p.running--
+
+	if !t.broken && !t.skipped && t.impErr == nil {
+		p.resHub[id] = t.res
+	}
+
+	p.release(t)
+
+	switch {
+	case t.broken, t.skipped:
+	case t.expect == parser.ExpectSkip:
+		p.skip(id, skipTarget(t.cfg, t.cfg.UnwrapExec()))
+	case t.impErr != nil:
+		p.stop(t.impErr)
+	case t.expect == parser.ExpectCrash:
+		p.stop(nil)
+	case t.expect >= 0:
+		if p.jumpFrom == parser.Error || id < p.jumpFrom {
+			p.jump, p.jumpFrom = t.expect, id
+		}
+		p.stop(nil)
+	}
+}
+
+// release releases tasks which wait for executed task.
+func (p *parallel) release(t *task) {
+	for _, n := range t.next {
+		nt := p.tasks[n]
+		nt.wait--
+		if nt.wait == 0 && nt.state == taskPending && !isAlways(nt.cfg) {
+			p.ready = append(p.ready, n)
+		}
+	}
+}
+
+// skip skips pending tasks between config and target of its 'fail=skip:<id>'.
+// Running and executed tasks are not changed. Not added tasks are skipped in add.
+// Teardown tasks are not skipped.
+func (p *parallel) skip(from, to int) {
+	if to > p.skipTo {
+		p.skipTo, p.skipFrom = to, from
+	}
+
+	for _, t := range p.tasks[from+1:] {
+		if t.cfg.GetID() >= to {
+			break
+		}
+		if t.state == taskPending && !isAlways(t.cfg) {
+			p.skipTask(t, from)
+		}
+	}
+}
+
+// skipTask marks pending task as skipped by config from.
+func (p *parallel) skipTask(t *task, from int) {
+	id := t.cfg.GetID()
+	t.state = taskDone
+	t.skipped = true
+	t.skipMsg = fmt.Sprintf("skipped by config %d", from)
+	t.res = &transport.Result{CfgID: id, Skipped: true}
+
+	p.ready = slices.DeleteFunc(p.ready, func(r int) bool { return r == id })
+	p.release(t)
+}
+
+// stop stops starting of new tasks. Executing tasks are finished.
+func (p *parallel) stop(err error) {
+	p.stopped = true
+	p.ready = nil
+	if err != nil && p.err == nil {
+		p.err = err
+	}
+}
+
+// commit prints, reports and writes executed tasks in file order.
+// It stops at first not executed task, if final is false.
+// Else not executed tasks are skipped and rest of file is copied as is.
+func (p *parallel) commit(final bool) {
+	for p.commited < len(p.tasks) {
+		t := p.tasks[p.commited]
+		if t.state != taskDone {
+			if !final {
+				return
+			}
+			if isAlways(t.cfg) {
+				p.teardown = append(p.teardown, t)
+			}
+			p.isCrashed = true
+			p.commited++
+			continue
+		}
+
+		// after crash or jump only teardown tasks are committed, like in sequential mode
+		if p.isCrashed && !isAlways(t.cfg) {
+			p.commited++
+			continue
+		}
+
+		p.commitTask(t)
+		p.commited++
+	}
+}
+
+// commitTask prints, reports and writes one executed task.
+func (p *parallel) commitTask(t *task) {
+	if t.broken {
+		// config stays in file as is
+		if !p.isCrashed {
+			p.toFile.Write(t.orig.Clone())
+		}
+		return
+	}
+
+	if t.skipped {
+		p.toPrint.Write(t.res)
+		p.rep.add(skipCase(p.cPath, t.cfg, t.skipMsg, t.ifErr))
+		// config stays in file with old response
+		if !p.isCrashed {
+			p.toFile.Write(t.orig.Clone())
+		}
+		return
+	}
+
+	if t.impErr != nil {
+		p.isCrashed = true // for 'copyTail'
+		return
+	}
+
+	if t.rows != nil {
+		for _, r := range t.rows {
+			p.toPrint.Write(r.res)
+			p.rep.add(r.c)
+		}
+	} else {
+		p.toPrint.Write(t.res)
+
+		if t.res.Info.Code != importConfigCode {
+			p.rep.add(newCase(p.cPath, t.cfg, t.res, t.expect, t.fail, t.sendErr))
+		}
+	}
+
+	if !p.isCrashed {
+		cfgToFile := t.orig.Clone()
+		cfgToFile.Update(fileResponse(t.res), t.res.Cookie)
+		p.toFile.Write(cfgToFile)
+	}
+
+	if t.expect == parser.ExpectCrash || t.expect >= 0 {
+		p.isCrashed = true
+	}
+}
+
+// runJump executes target config of jump.
+// Like in sequential mode, run ends after target config.
+func (p *parallel) runJump() {
+	const op = "core.parallel.runJump"
+
+	var cfg config.Config
+	if err := parser.ParseFindConfig(p.sData, p.defs, &cfg, p.jump); err != nil {
+		p.log.Error("Failed to find config",
+			zap.String("op", op),
+			zap.Int("id", p.jumpFrom),
+			zap.Int("target", p.jump),
+			zap.Error(err))
+		return
+	}
+	defer cfg.Release()
+
+	t := &task{cfg: cfg, orig: allocConfig(cfg)}
+	p.exec(t)
+	p.commitTask(t)
+
+	if t.impErr != nil && p.err == nil {
+		p.err = t.impErr
+	}
+}
+
+// runTeardown executes teardown tasks in file order.
+// Target of jump is not executed twice.
+func (p *parallel) runTeardown() {
+	const op = "core.parallel.runTeardown"
+
+	slices.SortFunc(p.teardown, func(a, b *task) int {
+		return a.cfg.GetID() - b.cfg.GetID()
+	})
+
+	for _, t := range p.teardown {
+		if t.cfg.GetID() == p.jump {
+			continue
+		}
+
+		p.log.Debug("teardown config",
+			zap.String("op", op),
+			zap.String("name", t.cfg.GetName()),
+			zap.Int("id", t.cfg.GetID()))
+
+		p.exec(t)
+		p.commitTask(t)
+
+		if t.impErr != nil && p.err == nil {
+			p.err = t.impErr
+		}
+	}
+}
+
+// isBarrier reports whether config changes shared state or may skip next configs.
+// Such config is executed alone: after all previous configs and before all next.
+func isBarrier(cfg config.Config) bool {
+	if _, ok := cfg.(*config.ImportConfig); ok {
+		return true
+	}
+	if exec := cfg.UnwrapExec(); exec != nil {
+		if len(exec.GetCapture()) != 0 {
+			return true
+		}
+		// skip, crash and jump change which next configs are executed
+		if action, _ := parser.ParseFail(expectField(cfg, exec)); action == parser.ExpectSkip || action == parser.ExpectCrash || action >= 0 {
+			return true
+		}
+	}
+	return len(cfg.GetVars()) != 0 || len(cfg.GetEnvs()) != 0 || len(cfg.GetCapture()) != 0 || hasForEach(cfg)
+}
+
+// allocConfig returns copy of config outside of pre-allocated buffers.
+func allocConfig(cfg config.Config) config.Config {
+	cp := config.Alloc(cfg)
+	if r, ok := cp.(*config.RepeatConfig); ok && r.Orig != nil {
+		r.Orig = config.Alloc(r.Orig)
+	}
+	return cp
+}
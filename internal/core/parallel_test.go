@@ -0,0 +1,413 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Votline/Gurl-cli/internal/config"
+	"github.com/Votline/Gurl-cli/internal/transport"
+
+	"go.uber.org/zap"
+)
+
+// testServer is a server which records paths of requests.
+type testServer struct {
+	*httptest.Server
+	mu    sync.Mutex
+	paths []string
+	hits  map[string]int
+}
+
+// newTestServer starts server with endpoints for config files:
+// '/slow' sleeps before response, '/status/<code>' responds with code,
+// '/json' responds with token, '/items' responds with array of items, '/flaky/<n>' responds with 503 and pending state
+// to first n requests and with done state after, '/echo' responds with its query.
+func newTestServer(t testing.TB) *testServer {
+	s := &testServer{hits: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.paths = append(s.paths, r.URL.Path)
+		s.hits[r.URL.Path]++
+		hits := s.hits[r.URL.Path]
+		s.mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/slow":
+			time.Sleep(50 * time.Millisecond)
+			fmt.Fprint(w, "slow")
+		case strings.HasPrefix(r.URL.Path, "/status/"):
+			var code int
+			fmt.Sscan(r.URL.Path[len("/status/"):], &code)
+			w.WriteHeader(code)
+			fmt.Fprint(w, "status")
+		case r.URL.Path == "/json":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"token":"tok123"}`)
+		case r.URL.Path == "/items":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"items":[{"id":7},{"id":"x"},{"id":9}]}`)
+		case strings.HasPrefix(r.URL.Path, "/flaky/"):
+			var fails int
+			fmt.Sscan(r.URL.Path[len("/flaky/"):], &fails)
+			w.Header().Set("Content-Type", "application/json")
+			if hits <= fails {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprint(w, `{"state":"pending"}`)
+				return
+			}
+			fmt.Fprint(w, `{"state":"done"}`)
+		default:
+			fmt.Fprintf(w, "echo %s", r.URL.RawQuery)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// sent returns and resets paths of requests in order of receiving.
+func (s *testServer) sent() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths := s.paths
+	s.paths = nil
+	return paths
+}
+
+// runResult is a outcome of one run of config file.
+type runResult struct {
+	cases []string
+	file  string
+	paths []string
+}
+
+// runFile writes config file with URL of server and runs it.
+// Returns cases, file after run and sent requests.
+func runFile(t testing.TB, s *testServer, src string, parallel int) runResult {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.gurlf")
+	if err := os.WriteFile(path, []byte(strings.ReplaceAll(src, "{URL}", s.URL)), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	log := zap.NewNop()
+	config.Init()
+	ses, err := transport.NewSession("", "", log)
+	if err != nil {
+		t.Fatalf("new session: %v", err)
+	}
+	defer ses.Close()
+
+	rep := &Report{}
+	opts := Options{DisablePrint: true, Parallel: parallel}
+	if _, err := handleConfig(path, opts, make(map[string][]byte), nil, ses, rep, log); err != nil {
+		t.Fatalf("handle config: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+
+	var cases []string
+	for _, c := range rep.Cases() {
+		cases = append(cases, fmt.Sprintf("%d %s %d %s", c.ID, c.Name, c.Code, c.Outcome))
+	}
+	return runResult{cases: cases, file: string(data), paths: s.sent()}
+}
+
+func TestParallel(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected []string
+		notSent  []string
+		last     string
+	}{
+		{
+			"commit in file order",
+			`[a]
+URL:{URL}/slow
+ID:0
+Type:http
+[\a]
+
+[b]
+URL:{URL}/echo?b
+ID:1
+Type:http
+[\b]
+
+[c]
+URL:{URL}/echo?c
+ID:2
+Type:http
+[\c]
+`,
+			[]string{"0 a 200 passed", "1 b 200 passed", "2 c 200 passed"},
+			nil,
+			"",
+		},
+		{
+			"dependency graph",
+			`[login]
+URL:{URL}/json
+ID:0
+Type:http
+[\login]
+
+[use]
+URL:{URL}/echo?token={RESPONSE id=0 json:token}
+ID:1
+Type:http
+[\use]
+
+[g1]
+URL:{URL}/slow
+ID:2
+Type:http
+Group:g
+[\g1]
+
+[g2]
+URL:{URL}/echo?g2
+ID:3
+Type:http
+Group:g
+[\g2]
+
+[after]
+URL:{URL}/echo?after
+ID:4
+Type:http
+After:g, 1
+[\after]
+`,
+			[]string{"0 login 200 passed", "1 use 200 passed", "2 g1 200 passed", "3 g2 200 passed", "4 after 200 passed"},
+			nil,
+			"",
+		},
+		{
+			"crash barrier",
+			`[a]
+URL:{URL}/slow
+ID:0
+Type:http
+[\a]
+
+[b]
+URL:{URL}/status/500
+ID:1
+Type:http
+Expect:2xx;fail=crash
+[\b]
+
+[c]
+URL:{URL}/never
+ID:2
+Type:http
+[\c]
+
+[finally]
+URL:{URL}/echo?finally
+ID:3
+Type:http
+[\finally]
+`,
+			[]string{"0 a 200 passed", "1 b 500 crashed", "3 finally 200 passed"},
+			[]string{"/never"},
+			"",
+		},
+		{
+			"jump",
+			`[a]
+URL:{URL}/slow
+ID:0
+Type:http
+[\a]
+
+[b]
+URL:{URL}/status/500
+ID:1
+Type:http
+Expect:2xx;fail=3
+[\b]
+
+[c]
+URL:{URL}/never
+ID:2
+Type:http
+[\c]
+
+[d]
+URL:{URL}/echo?d
+ID:3
+Type:http
+[\d]
+
+[e]
+URL:{URL}/never
+ID:4
+Type:http
+[\e]
+`,
+			[]string{"0 a 200 passed", "1 b 500 failed", "3 d 200 passed"},
+			[]string{"/never"},
+			"",
+		},
+		{
+			"skip",
+			`[a]
+URL:{URL}/status/500
+ID:0
+Type:http
+Expect:2xx;fail=skip:3
+[\a]
+
+[b]
+URL:{URL}/never
+ID:1
+Type:http
+[\b]
+
+[clean]
+URL:{URL}/echo?clean
+ID:2
+Type:http
+Always:true
+[\clean]
+
+[d]
+URL:{URL}/echo?d
+ID:3
+Type:http
+[\d]
+`,
+			[]string{"0 a 500 failed", "1 b 0 skipped", "2 clean 200 passed", "3 d 200 passed"},
+			[]string{"/never"},
+			"",
+		},
+		{
+			"teardown order",
+			`[a]
+URL:{URL}/slow
+ID:0
+Type:http
+[\a]
+
+[clean]
+URL:{URL}/echo?clean
+ID:1
+Type:http
+Always:true
+[\clean]
+
+[b]
+URL:{URL}/status/500
+ID:2
+Type:http
+Expect:2xx;fail=crash
+[\b]
+
+[c]
+URL:{URL}/never
+ID:3
+Type:http
+[\c]
+
+[finally]
+URL:{URL}/echo?finally
+ID:4
+Type:http
+[\finally]
+`,
+			[]string{"0 a 200 passed", "1 clean 200 passed", "2 b 500 crashed", "4 finally 200 passed"},
+			[]string{"/never"},
+			"",
+		},
+		{
+			"teardown after other configs",
+			`[a]
+URL:{URL}/slow
+ID:0
+Type:http
+[\a]
+
+[finally]
+URL:{URL}/finally
+ID:1
+Type:http
+[\finally]
+
+[c]
+URL:{URL}/slow
+ID:2
+Type:http
+[\c]
+`,
+			[]string{"0 a 200 passed", "1 finally 200 passed", "2 c 200 passed"},
+			nil,
+			"/finally",
+		},
+	}
+
+	s := newTestServer(t)
+	for _, tt := range tests {
+		seq := runFile(t, s, tt.src, 1)
+		if !slices.Equal(seq.cases, tt.expected) {
+			t.Errorf("[%s]: expected %q, but got %q", tt.name, tt.expected, seq.cases)
+		}
+
+		for _, n := range []int{2, 4} {
+			par := runFile(t, s, tt.src, n)
+			if !slices.Equal(par.cases, seq.cases) {
+				t.Errorf("[%s] parallel %d: expected cases %q, but got %q", tt.name, n, seq.cases, par.cases)
+			}
+			if par.file != seq.file {
+				t.Errorf("[%s] parallel %d: expected file\n%s\nbut got\n%s", tt.name, n, seq.file, par.file)
+			}
+			for _, p := range tt.notSent {
+				if slices.Contains(seq.paths, p) || slices.Contains(par.paths, p) {
+					t.Errorf("[%s] parallel %d: %s is sent, sequentially %q, parallel %q", tt.name, n, p, seq.paths, par.paths)
+				}
+			}
+			if tt.last != "" && par.paths[len(par.paths)-1] != tt.last {
+				t.Errorf("[%s] parallel %d: expected %s last, but got %q", tt.name, n, tt.last, par.paths)
+			}
+		}
+	}
+}
+
+func BenchmarkParallel(b *testing.B) {
+	src := `[a]
+URL:{URL}/echo?a
+ID:0
+Type:http
+[\a]
+
+[b]
+URL:{URL}/echo?b
+ID:1
+Type:http
+[\b]
+
+[c]
+URL:{URL}/echo?c={RESPONSE id=0 body}
+ID:2
+Type:http
+[\c]
+`
+	s := newTestServer(b)
+	for b.Loop() {
+		runFile(b, s, src, 4)
+	}
+}
@@ -0,0 +1,97 @@
+// Package core profile.go loads profile and variables from command line.
+// They are initial variables of run and environments of process.
+package core
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Votline/Gurl-cli/internal/parser"
+
+	"github.com/Votline/Gurlf"
+)
+
+// profilesName is a default name of profiles file.
+// It is searched next to config file or in tested directory.
+const profilesName = "profiles.gurlf"
+
+// profile is a active profile with variables from command line.
+type profile struct {
+	// name is a name of profile, empty without '--profile'.
+	name string
+
+	// path is a path to profiles file.
+	path string
+
+	// vars is a values of profile overridden by '--var'.
+	vars map[string][]byte
+
+	// keys is a sorted keys of profile.
+	keys []string
+
+	// overrides is a keys from '--var' in order of flags.
+	overrides []string
+}
+
+// loadProfile accepts path of run and options.
+// It reads profile and '--var' values and sets them as environments,
+// so '{ENVIRONMENT key=... ; from=os}' resolves them too.
+func loadProfile(root string, opts Options) (*profile, error) {
+	const op = "core.loadProfile"
+
+	p := &profile{name: opts.Profile, path: opts.Profiles, vars: make(map[string][]byte)}
+	if p.path == "" {
+		p.path = filepath.Join(filepath.Dir(root), profilesName)
+		if info, err := os.Stat(root); err == nil && info.IsDir() {
+			p.path = filepath.Join(root, profilesName)
+		}
+	}
+
+	if p.name != "" {
+		sData, err := gurlf.ScanFile(p.path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan profiles %q: %w", op, p.path, err)
+		}
+		if p.vars, err = parser.ParseProfile(sData, p.name); err != nil {
+			return nil, fmt.Errorf("%s: %q: %w", op, p.path, err)
+		}
+		p.keys = slices.Sorted(maps.Keys(p.vars))
+	}
+
+	for _, kv := range opts.Vars {
+		key, val, err := parser.ParseVar(kv)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		p.vars[key] = val
+		p.overrides = append(p.overrides, key)
+	}
+
+	for key, val := range p.vars {
+		if err := os.Setenv(key, string(val)); err != nil {
+			return nil, fmt.Errorf("%s: set environment %q: %w", op, key, err)
+		}
+	}
+
+	return p, nil
+}
+
+// newVars returns copy of profile variables for one file.
+func (p *profile) newVars() map[string][]byte {
+	return maps.Clone(p.vars)
+}
+
+// print prints name and keys of active profile.
+// Values are not printed, they may be secrets.
+func (p *profile) print() {
+	if p.name != "" {
+		fmt.Printf("\033[90m[Profile %s] %s: %s\033[0m\n", p.name, p.path, strings.Join(p.keys, ", "))
+	}
+	if len(p.overrides) != 0 {
+		fmt.Printf("\033[90m[Vars] %s\033[0m\n", strings.Join(p.overrides, ", "))
+	}
+}
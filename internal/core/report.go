@@ -0,0 +1,500 @@
+// Package core report.go contains run report.
+// Collects outcome of every executed config and prints summary.
+package core
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Votline/Gurl-cli/internal/config"
+	"github.com/Votline/Gurl-cli/internal/parser"
+	"github.com/Votline/Gurl-cli/internal/transport"
+)
+
+// Outcomes of executed config.
+const (
+	// OutcomePassed for config without failed expectations.
+	OutcomePassed = "passed"
+
+	// OutcomeFailed for config with failed 'Expect' or 'Assert'.
+	OutcomeFailed = "failed"
+
+	// OutcomeCrashed for config with failed 'Expect' and 'fail=crash'.
+	OutcomeCrashed = "crashed"
+
+	// OutcomeError for config which can't be sent or processed.
+	OutcomeError = "error"
+
+	// OutcomeSkipped for config with false 'If' condition or skipped by 'fail=skip:<id>'.
+	OutcomeSkipped = "skipped"
+)
+
+// Case is a result of one executed config.
+type Case struct {
+	// File is a path of config file.
+	File string
+
+	// Name is a config name.
+	Name string
+
+	// ID is a config id.
+	ID int
+
+	// Type is a config type. Like 'http' or 'grpc'.
+	Type string
+
+	// Code is a response code.
+	Code int
+
+	// Duration is a request duration.
+	Duration time.Duration
+
+	// Outcome is a config outcome. Like OutcomePassed.
+	Outcome string
+
+	// Message is a failure message.
+	Message string
+}
+
+// Report collects cases of run.
+// Safe for concurrent use.
+type Report struct {
+	mu    sync.Mutex
+	cases []Case
+}
+
+// add appends case to report.
+func (r *Report) add(c Case) {
+	r.mu.Lock()
+	r.cases = append(r.cases, c)
+	r.mu.Unlock()
+}
+
+// Cases returns copy of collected cases.
+func (r *Report) Cases() []Case {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make([]Case, len(r.cases))
+	copy(res, r.cases)
+	return res
+}
+
+// Failed returns count of not passed and not skipped cases.
+func (r *Report) Failed() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cnt := 0
+	for _, c := range r.cases {
+		if c.failed() {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// failed reports whether case is failed.
+func (c Case) failed() bool {
+	return c.Outcome != OutcomePassed && c.Outcome != OutcomeSkipped
+}
+
+// newCase makes case from executed config.
+// id is a result of applyExpect, fail is a failure message.
+func newCase(file string, cfg config.Config, res *transport.Result, id int, fail string, err error) Case {
+	c := Case{
+		File:     file,
+		Name:     cfg.GetName(),
+		ID:       cfg.GetID(),
+		Type:     cfg.GetType(),
+		Code:     res.Info.Code,
+		Duration: res.Duration,
+		Outcome:  OutcomePassed,
+	}
+
+	if exec := cfg.UnwrapExec(); exec != nil && exec != cfg {
+		c.Type = exec.GetType()
+	}
+
+	switch {
+	case err != nil:
+		c.Outcome = OutcomeError
+		c.Message = err.Error()
+	case id == parser.ExpectCrash:
+		c.Outcome = OutcomeCrashed
+		c.Message = fail
+	case fail != "":
+		c.Outcome = OutcomeFailed
+		c.Message = fail
+	}
+
+	return c
+}
+
+// skipCase makes case from skipped config.
+// reason is a message of skip.
+// err is a error of invalid condition, then case is error.
+func skipCase(file string, cfg config.Config, reason string, err error) Case {
+	c := Case{
+		File:    file,
+		Name:    cfg.GetName(),
+		ID:      cfg.GetID(),
+		Type:    cfg.GetType(),
+		Outcome: OutcomeSkipped,
+		Message: reason,
+	}
+
+	if exec := cfg.UnwrapExec(); exec != nil && exec != cfg {
+		c.Type = exec.GetType()
+	}
+
+	if err != nil {
+		c.Outcome = OutcomeError
+		c.Message = err.Error()
+	}
+
+	return c
+}
+
+// findSuites accepts path to file or directory.
+// It returns sorted paths of all '.gurlf' files except profiles files.
+func findSuites(root string) ([]string, error) {
+	const op = "core.findSuites"
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("%s: stat %q: %w", op, root, err)
+	}
+	if !info.IsDir() {
+		return []string{root}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".gurlf") && d.Name() != profilesName {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: walk %q: %w", op, root, err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// printCases prints outcome of each case and summary of file.
+func printCases(file string, cases []Case) {
+	failed := 0
+	for _, c := range cases {
+		if c.failed() {
+			failed++
+		}
+	}
+
+	if failed == 0 {
+		fmt.Printf("\n\033[32mPASS\033[0m %s (%d configs)\n", file, len(cases))
+	} else {
+		fmt.Printf("\n\033[31mFAIL\033[0m %s (%d of %d configs failed)\n", file, failed, len(cases))
+	}
+
+	for _, c := range cases {
+		mark := "\033[32mok  \033[0m"
+		switch {
+		case c.Outcome == OutcomeSkipped:
+			mark = "\033[33m" + outcomeMark(c.Outcome) + "\033[0m"
+		case c.failed():
+			mark = "\033[31m" + outcomeMark(c.Outcome) + "\033[0m"
+		}
+
+		fmt.Printf("  %s [ID %d] %s \033[90m(%s, %d, %s)\033[0m\n",
+			mark, c.ID, c.Name, c.Type, c.Code, c.Duration.Round(time.Microsecond))
+		if c.Message != "" {
+			fmt.Printf("       \033[90m%s\033[0m\n", c.Message)
+		}
+	}
+}
+
+// printTotal prints summary of all files.
+func printTotal(files, cases, failed int) {
+	fmt.Println(strings.Repeat("-", 20))
+	if failed == 0 {
+		fmt.Printf("\033[32mPASS\033[0m %d files, %d configs\n", files, cases)
+		return
+	}
+	fmt.Printf("\033[31mFAIL\033[0m %d files, %d configs, %d failed\n", files, cases, failed)
+}
+
+// outcomeMark returns short mark of outcome.
+func outcomeMark(outcome string) string {
+	switch outcome {
+	case OutcomeFailed:
+		return "fail"
+	case OutcomeCrashed:
+		return "crsh"
+	case OutcomeError:
+		return "err "
+	case OutcomeSkipped:
+		return "skip"
+	}
+	return outcome
+}
+
+// Formats of report file.
+const (
+	// reportJUnit for JUnit XML report.
+	reportJUnit = "junit"
+
+	// reportJSON for JSON report.
+	reportJSON = "json"
+)
+
+// reportSpec is a parsed '--report' option.
+type reportSpec struct {
+	format string
+	path   string
+}
+
+// parseReports accepts report options, like 'junit=report.xml'.
+// It returns parsed options and error.
+func parseReports(raw []string) ([]reportSpec, error) {
+	const op = "core.parseReports"
+
+	specs := make([]reportSpec, 0, len(raw))
+	for _, r := range raw {
+		format, path, found := strings.Cut(r, "=")
+		if !found || path == "" {
+			return nil, fmt.Errorf("%s: invalid report %q: need <format>=<path>", op, r)
+		}
+
+		switch format {
+		case reportJUnit, reportJSON:
+		default:
+			return nil, fmt.Errorf("%s: unknown report format %q", op, format)
+		}
+
+		specs = append(specs, reportSpec{format: format, path: path})
+	}
+
+	return specs, nil
+}
+
+// writeReports writes cases to every report file.
+func writeReports(specs []reportSpec, cases []Case) error {
+	const op = "core.writeReports"
+
+	for _, spec := range specs {
+		f, err := os.Create(spec.path)
+		if err != nil {
+			return fmt.Errorf("%s: create file (path=%q): %w", op, spec.path, err)
+		}
+
+		switch spec.format {
+		case reportJUnit:
+			err = writeJUnit(f, cases)
+		case reportJSON:
+			err = writeJSON(f, cases)
+		}
+
+		if cErr := f.Close(); err == nil {
+			err = cErr
+		}
+		if err != nil {
+			return fmt.Errorf("%s: write %s report: %w", op, spec.format, err)
+		}
+	}
+
+	return nil
+}
+
+// junitSuites is a root of JUnit XML report.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+// junitSuite is a one config file in JUnit XML report.
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+// junitCase is a one executed config in JUnit XML report.
+type junitCase struct {
+	Name       string          `xml:"name,attr"`
+	Classname  string          `xml:"classname,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Failure    *junitFailure   `xml:"failure,omitempty"`
+	Error      *junitFailure   `xml:"error,omitempty"`
+	Skipped    *junitSkipped   `xml:"skipped,omitempty"`
+}
+
+// junitProperty is a property of test case.
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// junitFailure is a failure or error of test case.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitSkipped is a mark of skipped test case.
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// writeJUnit writes cases as JUnit XML.
+// Each config file is a test suite, each config is a test case.
+func writeJUnit(w io.Writer, cases []Case) error {
+	root := junitSuites{Name: "gurl-cli"}
+	var total time.Duration
+
+	idx := make(map[string]int)
+	suiteTime := make([]time.Duration, 0)
+	for _, c := range cases {
+		i, ok := idx[c.File]
+		if !ok {
+			i = len(root.Suites)
+			idx[c.File] = i
+			root.Suites = append(root.Suites, junitSuite{Name: c.File})
+			suiteTime = append(suiteTime, 0)
+		}
+		suite := &root.Suites[i]
+
+		jc := junitCase{
+			Name:      fmt.Sprintf("[ID %d] %s", c.ID, c.Name),
+			Classname: strings.TrimSuffix(c.File, filepath.Ext(c.File)),
+			Time:      seconds(c.Duration),
+			Properties: []junitProperty{
+				{Name: "id", Value: strconv.Itoa(c.ID)},
+				{Name: "type", Value: c.Type},
+				{Name: "code", Value: strconv.Itoa(c.Code)},
+				{Name: "outcome", Value: c.Outcome},
+			},
+		}
+
+		switch c.Outcome {
+		case OutcomeFailed, OutcomeCrashed:
+			jc.Failure = &junitFailure{Message: c.Message, Type: c.Outcome, Text: c.Message}
+			suite.Failures++
+			root.Failures++
+		case OutcomeError:
+			jc.Error = &junitFailure{Message: c.Message, Type: c.Outcome, Text: c.Message}
+			suite.Errors++
+			root.Errors++
+		case OutcomeSkipped:
+			jc.Skipped = &junitSkipped{Message: c.Message}
+			suite.Skipped++
+			root.Skipped++
+		}
+
+		suite.Cases = append(suite.Cases, jc)
+		suite.Tests++
+		root.Tests++
+		suiteTime[i] += c.Duration
+		total += c.Duration
+	}
+
+	for i := range root.Suites {
+		root.Suites[i].Time = seconds(suiteTime[i])
+	}
+	root.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// jsonReport is a root of JSON report.
+type jsonReport struct {
+	Total   int        `json:"total"`
+	Passed  int        `json:"passed"`
+	Failed  int        `json:"failed"`
+	Skipped int        `json:"skipped"`
+	Cases   []jsonCase `json:"cases"`
+}
+
+// jsonCase is a one executed config in JSON report.
+type jsonCase struct {
+	File       string  `json:"file"`
+	Name       string  `json:"name"`
+	ID         int     `json:"id"`
+	Type       string  `json:"type"`
+	Code       int     `json:"code"`
+	DurationMs float64 `json:"duration_ms"`
+	Outcome    string  `json:"outcome"`
+	Message    string  `json:"message,omitempty"`
+}
+
+// writeJSON writes cases as JSON.
+func writeJSON(w io.Writer, cases []Case) error {
+	rep := jsonReport{Total: len(cases), Cases: make([]jsonCase, 0, len(cases))}
+
+	for _, c := range cases {
+		switch {
+		case c.Outcome == OutcomePassed:
+			rep.Passed++
+		case c.Outcome == OutcomeSkipped:
+			rep.Skipped++
+		default:
+			rep.Failed++
+		}
+
+		rep.Cases = append(rep.Cases, jsonCase{
+			File:       c.File,
+			Name:       c.Name,
+			ID:         c.ID,
+			Type:       c.Type,
+			Code:       c.Code,
+			DurationMs: float64(c.Duration.Microseconds()) / 1000,
+			Outcome:    c.Outcome,
+			Message:    c.Message,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
+
+// seconds formats duration as seconds for JUnit.
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// reportCases is a cases of two files with every outcome.
+var reportCases = []Case{
+	{File: "a.gurlf", Name: "login", ID: 0, Type: "http", Code: 200, Duration: 1500 * time.Millisecond, Outcome: OutcomePassed},
+	{File: "a.gurlf", Name: "get", ID: 1, Type: "http", Code: 500, Duration: 250 * time.Millisecond, Outcome: OutcomeFailed, Message: "expected 200, but got 500"},
+	{File: "b.gurlf", Name: "call", ID: 0, Type: "grpc", Outcome: OutcomeError, Message: "dial <refused>"},
+	{File: "b.gurlf", Name: "opt", ID: 1, Type: "http", Outcome: OutcomeSkipped, Message: "condition is false"},
+}
+
+func TestParseReports(t *testing.T) {
+	tests := []struct {
+		input    []string
+		expected []reportSpec
+		err      bool
+	}{
+		{nil, []reportSpec{}, false},
+		{
+			[]string{"junit=report.xml", "json=out/report.json"},
+			[]reportSpec{{reportJUnit, "report.xml"}, {reportJSON, "out/report.json"}},
+			false,
+		},
+		{[]string{"junit"}, nil, true},
+		{[]string{"junit="}, nil, true},
+		{[]string{"html=report.html"}, nil, true},
+	}
+
+	for i, tt := range tests {
+		res, err := parseReports(tt.input)
+		if tt.err {
+			if err == nil {
+				t.Errorf("[%d]: expected error, but got nil", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d]: unexpected error: %v", i, err)
+			continue
+		}
+		if !slices.Equal(res, tt.expected) {
+			t.Errorf("[%d]: expected %v, but got %v", i, tt.expected, res)
+		}
+	}
+}
+
+func BenchmarkParseReports(b *testing.B) {
+	raw := []string{"junit=report.xml", "json=report.json"}
+	for b.Loop() {
+		parseReports(raw)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="gurl-cli" tests="4" failures="1" errors="1" skipped="1" time="1.750">
+  <testsuite name="a.gurlf" tests="2" failures="1" errors="0" skipped="0" time="1.750">
+    <testcase name="[ID 0] login" classname="a" time="1.500">
+      <properties>
+        <property name="id" value="0"></property>
+        <property name="type" value="http"></property>
+        <property name="code" value="200"></property>
+        <property name="outcome" value="passed"></property>
+      </properties>
+    </testcase>
+    <testcase name="[ID 1] get" classname="a" time="0.250">
+      <properties>
+        <property name="id" value="1"></property>
+        <property name="type" value="http"></property>
+        <property name="code" value="500"></property>
+        <property name="outcome" value="failed"></property>
+      </properties>
+      <failure message="expected 200, but got 500" type="failed">expected 200, but got 500</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="b.gurlf" tests="2" failures="0" errors="1" skipped="1" time="0.000">
+    <testcase name="[ID 0] call" classname="b" time="0.000">
+      <properties>
+        <property name="id" value="0"></property>
+        <property name="type" value="grpc"></property>
+        <property name="code" value="0"></property>
+        <property name="outcome" value="error"></property>
+      </properties>
+      <error message="dial &lt;refused&gt;" type="error">dial &lt;refused&gt;</error>
+    </testcase>
+    <testcase name="[ID 1] opt" classname="b" time="0.000">
+      <properties>
+        <property name="id" value="1"></property>
+        <property name="type" value="http"></property>
+        <property name="code" value="0"></property>
+        <property name="outcome" value="skipped"></property>
+      </properties>
+      <skipped message="condition is false"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+
+	var buf bytes.Buffer
+	if err := writeJUnit(&buf, reportCases); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, buf.String())
+	}
+}
+
+func BenchmarkWriteJUnit(b *testing.B) {
+	var buf bytes.Buffer
+	for b.Loop() {
+		buf.Reset()
+		writeJUnit(&buf, reportCases)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	expected := `{
+  "total": 4,
+  "passed": 1,
+  "failed": 2,
+  "skipped": 1,
+  "cases": [
+    {
+      "file": "a.gurlf",
+      "name": "login",
+      "id": 0,
+      "type": "http",
+      "code": 200,
+      "duration_ms": 1500,
+      "outcome": "passed"
+    },
+    {
+      "file": "a.gurlf",
+      "name": "get",
+      "id": 1,
+      "type": "http",
+      "code": 500,
+      "duration_ms": 250,
+      "outcome": "failed",
+      "message": "expected 200, but got 500"
+    },
+    {
+      "file": "b.gurlf",
+      "name": "call",
+      "id": 0,
+      "type": "grpc",
+      "code": 0,
+      "duration_ms": 0,
+      "outcome": "error",
+      "message": "dial \u003crefused\u003e"
+    },
+    {
+      "file": "b.gurlf",
+      "name": "opt",
+      "id": 1,
+      "type": "http",
+      "code": 0,
+      "duration_ms": 0,
+      "outcome": "skipped",
+      "message": "condition is false"
+    }
+  ]
+}
+`
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, reportCases); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, buf.String())
+	}
+}
+
+func BenchmarkWriteJSON(b *testing.B) {
+	var buf bytes.Buffer
+	for b.Loop() {
+		buf.Reset()
+		writeJSON(&buf, reportCases)
+	}
+}
+
+func TestTest(t *testing.T) {
+	files := map[string]string{
+		"pass.gurlf": `[ok]
+URL:{URL}/echo?ok
+ID:0
+Type:http
+Expect:200
+[\ok]
+`,
+		"suite/fail.gurlf": `[ok]
+URL:{URL}/echo?ok
+ID:0
+Type:http
+[\ok]
+
+[bad]
+URL:{URL}/status/500
+ID:1
+Type:http
+Expect:2xx
+[\bad]
+`,
+	}
+
+	s := newTestServer(t)
+	dir := t.TempDir()
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		files[name] = strings.ReplaceAll(src, "{URL}", s.URL)
+		if err := os.WriteFile(path, []byte(files[name]), 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	report := filepath.Join(t.TempDir(), "report.xml")
+	opts := Options{DisablePrint: true, Reports: []string{"junit=" + report}}
+	err := Test(dir, opts, zap.NewNop())
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 configs failed") {
+		t.Errorf("expected error with %q, but got %v", "1 of 3 configs failed", err)
+	}
+
+	for name, src := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("read file: %v", err)
+		}
+		if string(data) != src {
+			t.Errorf("%s: expected file unchanged, but got\n%s", name, data)
+		}
+	}
+
+	data, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	var root junitSuites
+	if err := xml.Unmarshal(data, &root); err != nil {
+		t.Fatalf("unmarshal report: %v", err)
+	}
+	if root.Tests != 3 || root.Failures != 1 || root.Errors != 0 || root.Skipped != 0 {
+		t.Errorf("expected 3 tests, 1 failure, but got %+v", root)
+	}
+
+	var outcomes []string
+	for _, suite := range root.Suites {
+		for _, c := range suite.Cases {
+			outcomes = append(outcomes, filepath.Base(suite.Name)+" "+c.Name+" "+c.Properties[3].Value)
+		}
+	}
+	expected := []string{"pass.gurlf [ID 0] ok passed", "fail.gurlf [ID 0] ok passed", "fail.gurlf [ID 1] bad failed"}
+	slices.Sort(outcomes)
+	slices.Sort(expected)
+	if !slices.Equal(outcomes, expected) {
+		t.Errorf("expected %q, but got %q", expected, outcomes)
+	}
+}
@@ -0,0 +1,222 @@
+// Package core rows.go contains data-driven execution of configs.
+// Config with 'DataFile' is executed once per row of data file.
+// Config with 'ForEach' is executed once per element of array.
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"unsafe"
+
+	"github.com/Votline/Gurl-cli/internal/config"
+	"github.com/Votline/Gurl-cli/internal/parser"
+	"github.com/Votline/Gurl-cli/internal/transport"
+
+	"go.uber.org/zap"
+)
+
+// rowRun is a result of one row of 'DataFile'.
+type rowRun struct {
+	// res is a response of row.
+	res *transport.Result
+
+	// expect is a result of applyExpect.
+	expect int
+
+	// fail is a failure message of applyExpect.
+	fail string
+
+	// c is a report case of row.
+	c Case
+}
+
+// rowSummary is a summary of rows.
+// It is written to 'Response' of config.
+type rowSummary struct {
+	Rows   int   `json:"rows"`
+	Passed int   `json:"passed"`
+	Failed int   `json:"failed"`
+	Codes  []int `json:"codes"`
+}
+
+// sendRows executes config once per row of 'DataFile'.
+// Each row is expanded on a copy of tmpl and checked by 'Expect'.
+// Rows stop after first crash, skip or jump.
+// Summary of rows is stored in res.
+// Returns runs of rows and error of data file.
+func sendRows(cPath string, cfg, execCfg, tmpl config.Config, resHub *[]*transport.Result, vars map[string][]byte, trnsp *transport.Transport, res *transport.Result, dp bool, log *zap.Logger) ([]rowRun, error) {
+	const op = "core.sendRows"
+
+	rows, err := readRows(execCfg.GetDataFile())
+	if err != nil {
+		log.Error("Failed to read data file",
+			zap.String("op", op),
+			zap.String("name", cfg.GetName()),
+			zap.Int("id", cfg.GetID()),
+			zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sendIters(cPath, cfg, tmpl, resHub, vars, false, trnsp, res, dp, log, len(rows), func(i int) (map[string][]byte, string) {
+		return rows[i], ""
+	})
+}
+
+// sendEach executes config once per element of 'ForEach'.
+// Element and its index are stored in vars before each iteration.
+// Old values of these variables are restored after all iterations.
+// Returns runs of elements and error of invalid field.
+func sendEach(cPath string, cfg, execCfg, tmpl config.Config, resHub *[]*transport.Result, vars map[string][]byte, trnsp *transport.Transport, res *transport.Result, dp bool, log *zap.Logger) ([]rowRun, error) {
+	const op = "core.sendEach"
+
+	fe, err := parser.ParseForEach(execCfg.GetForEach())
+	if err == nil && len(execCfg.GetDataFile()) != 0 {
+		err = fmt.Errorf("%s: 'ForEach' can't be used with 'DataFile'", op)
+	}
+	if err != nil {
+		log.Error("Failed to parse for each",
+			zap.String("op", op),
+			zap.String("name", cfg.GetName()),
+			zap.Int("id", cfg.GetID()),
+			zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	name, index := string(fe.Name), string(fe.Index)
+	for _, key := range []string{name, index} {
+		if old, ok := vars[key]; ok {
+			defer func() { vars[key] = old }()
+		} else {
+			defer delete(vars, key)
+		}
+	}
+
+	return sendIters(cPath, cfg, tmpl, resHub, vars, true, trnsp, res, dp, log, len(fe.Items), func(i int) (map[string][]byte, string) {
+		vars[name] = bytes.Clone(fe.Items[i])
+		vars[index] = strconv.AppendInt(nil, int64(i), 10)
+		return nil, name + "=" + string(fe.Items[i])
+	})
+}
+
+// sendIters executes config n times.
+// next is called before each iteration with its index.
+// It returns row of 'DataFile' and label of 'ForEach' element.
+// each reports whether iterations are elements of 'ForEach'.
+// Each iteration is expanded on a copy of tmpl and checked by 'Expect'.
+// Iterations stop after first crash, skip or jump.
+// Summary of iterations is stored in res.
+// Returns runs of iterations and error of summary.
+func sendIters(cPath string, cfg, tmpl config.Config, resHub *[]*transport.Result, vars map[string][]byte, each bool, trnsp *transport.Transport, res *transport.Result, dp bool, log *zap.Logger, n int, next func(i int) (map[string][]byte, string)) ([]rowRun, error) {
+	const op = "core.sendIters"
+
+	sum := rowSummary{Codes: make([]int, 0, n)}
+	runs := make([]rowRun, 0, n)
+	for i := range n {
+		row, item := next(i)
+
+		rCfg := allocConfig(tmpl)
+		rExec := rCfg.UnwrapExec()
+		applyDeps(rCfg, resHub, vars, row, each, log)
+		applySettings(rCfg, rExec, log)
+
+		log.Debug("processing iteration",
+			zap.String("op", op),
+			zap.String("name", cfg.GetName()),
+			zap.Int("id", cfg.GetID()),
+			zap.Int("row", i+1),
+			zap.String("item", item))
+
+		r := new(transport.Result)
+		sendErr := sendUntil(rCfg, rExec, tmpl, resHub, vars, row, each, trnsp, r, dp, log)
+		r.CfgID = cfg.GetID()
+		r.Row = i + 1
+		r.Item = item
+		if sendErr == nil {
+			applyCapture(rCfg, rExec, r, vars, log)
+		}
+
+		id, fail := applyExpect(rCfg, rExec, r, log)
+		c := newCase(cPath, rCfg, r, id, fail, sendErr)
+		c.Name = fmt.Sprintf("%s[%d]", c.Name, r.Row)
+		runs = append(runs, rowRun{res: r, expect: id, fail: fail, c: c})
+
+		sum.Rows++
+		sum.Codes = append(sum.Codes, r.Info.Code)
+		if c.Outcome == OutcomePassed {
+			sum.Passed++
+		} else {
+			sum.Failed++
+		}
+
+		res.Info = r.Info
+		res.Cookie = r.Cookie
+		res.Duration += r.Duration
+
+		if id == parser.ExpectCrash || id == parser.ExpectSkip || id >= 0 {
+			break
+		}
+	}
+
+	raw, err := json.Marshal(sum)
+	if err != nil {
+		return runs, fmt.Errorf("%s: marshal summary: %w", op, err)
+	}
+	res.Raw = raw
+	res.IsJSON = true
+	res.Size = len(raw)
+
+	return runs, nil
+}
+
+// hasForEach reports whether config or its exec config has 'ForEach'.
+func hasForEach(cfg config.Config) bool {
+	if exec := cfg.UnwrapExec(); exec != nil && len(exec.GetForEach()) != 0 {
+		return true
+	}
+	return len(cfg.GetForEach()) != 0
+}
+
+// readRows reads rows of data file.
+// Format is detected by file extension.
+func readRows(path []byte) ([]map[string][]byte, error) {
+	const op = "core.readRows"
+
+	pStr := unsafe.String(unsafe.SliceData(path), len(path))
+	format := parser.DataFormat(pStr)
+	if format == parser.Error {
+		return nil, fmt.Errorf("%s: unknown format of data file %q", op, pStr)
+	}
+
+	data, err := os.ReadFile(pStr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: read %q: %w", op, pStr, err)
+	}
+
+	var rows []map[string][]byte
+	if err := parser.ParseData(data, format, func(row map[string][]byte) {
+		rows = append(rows, row)
+	}); err != nil {
+		return nil, fmt.Errorf("%s: parse %q: %w", op, pStr, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%s: no rows in %q", op, pStr)
+	}
+
+	return rows, nil
+}
+
+// rowsOutcome returns outcome of config by its rows.
+// It is outcome of last row, if it crashed, skipped or jumped. Otherwise config is done.
+func rowsOutcome(runs []rowRun) (int, string) {
+	if len(runs) == 0 {
+		return parser.ExpectDone, ""
+	}
+	last := runs[len(runs)-1]
+	if last.expect == parser.ExpectCrash || last.expect == parser.ExpectSkip || last.expect >= 0 {
+		return last.expect, last.fail
+	}
+	return parser.ExpectDone, ""
+}
@@ -0,0 +1,258 @@
+// Package parser assert.go parse and check assertions.
+// Assertions are lines of 'Assert' field.
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Operators of assertion.
+const (
+	// OpEq for '==' or '='.
+	OpEq = iota
+
+	// OpNe for '!='.
+	OpNe
+
+	// OpLt for '<'.
+	OpLt
+
+	// OpLe for '<='.
+	OpLe
+
+	// OpGt for '>'.
+	OpGt
+
+	// OpGe for '>='.
+	OpGe
+
+	// OpContains for 'contains'.
+	OpContains
+
+	// OpNotContains for '!contains'.
+	OpNotContains
+
+	// OpMatches for 'matches'. Value is a regexp.
+	OpMatches
+
+	// OpExists for 'exists'. Value is not needed.
+	OpExists
+
+	// OpNotExists for '!exists'. Value is not needed.
+	OpNotExists
+)
+
+// Assertion is a one line of 'Assert' field.
+type Assertion struct {
+	// Line is a raw assertion line. Used for reports.
+	Line []byte
+
+	// Kind is a kind of checked value. Like RespJSON or RespHeader.
+	Kind int
+
+	// Arg is a argument of checked value. Like json path or header name.
+	Arg []byte
+
+	// Op is a assertion operator. Like OpEq or OpContains.
+	Op int
+
+	// Want is a expected value.
+	Want []byte
+}
+
+// ParseAssert accepts assert field from config.
+// Calls yield for each assertion.
+// Line must be like 'json:user.role == admin' or 'duration < 300ms'.
+// Empty lines and lines starting with '#' are skipped.
+func ParseAssert(data []byte, yield func(Assertion)) error {
+	const op = "parser.ParseAssert"
+
+	var err error
+	RangeByByte(data, '\n', func(start, end int) {
+		if err != nil {
+			return
+		}
+
+		line := data[start:end]
+		trimBytes(&line, isSpace)
+		if len(line) == 0 || line[0] == '#' {
+			return
+		}
+
+		var a Assertion
+		if a, err = parseAssertLine(line); err != nil {
+			err = fmt.Errorf("%s: %w", op, err)
+			return
+		}
+		yield(a)
+	})
+
+	return err
+}
+
+// parseAssertLine parses one assertion line.
+func parseAssertLine(line []byte) (Assertion, error) {
+	const op = "parser.parseAssertLine"
+
+	a := Assertion{Line: line}
+	rest := line
+
+	sel := nextWord(&rest)
+	a.Kind, a.Arg = parseSelector(sel)
+	if a.Kind == Error {
+		return a, fmt.Errorf("%s: unknown value %q", op, sel)
+	}
+	if (a.Kind == RespHeader || a.Kind == RespTrailer) && len(a.Arg) == 0 {
+		return a, fmt.Errorf("%s: no name in %q", op, sel)
+	}
+
+	opWord := nextWord(&rest)
+	if a.Op = parseOp(opWord); a.Op == Error {
+		return a, fmt.Errorf("%s: unknown operator %q in %q", op, opWord, line)
+	}
+
+	trimBytes(&rest, isSpace)
+	if len(rest) > 1 && (rest[0] == '"' || rest[0] == '\'') && rest[len(rest)-1] == rest[0] {
+		rest = rest[1 : len(rest)-1]
+	}
+	a.Want = rest
+
+	switch a.Op {
+	case OpExists, OpNotExists:
+		if len(a.Want) != 0 {
+			return a, fmt.Errorf("%s: unexpected value in %q", op, line)
+		}
+	case OpMatches:
+		if _, err := regexp.Compile(string(a.Want)); err != nil {
+			return a, fmt.Errorf("%s: invalid regexp in %q: %w", op, line, err)
+		}
+	case OpLt, OpLe, OpGt, OpGe:
+		if _, ok := assertNum(a.Kind, a.Want); !ok {
+			return a, fmt.Errorf("%s: not a number in %q", op, line)
+		}
+	}
+
+	return a, nil
+}
+
+// parseOp returns assertion operator by its word.
+// Returns Error if operator is unknown.
+func parseOp(word []byte) int {
+	switch string(word) {
+	case "==", "=":
+		return OpEq
+	case "!=":
+		return OpNe
+	case "<":
+		return OpLt
+	case "<=":
+		return OpLe
+	case ">":
+		return OpGt
+	case ">=":
+		return OpGe
+	case "contains":
+		return OpContains
+	case "!contains":
+		return OpNotContains
+	case "matches", "~":
+		return OpMatches
+	case "exists":
+		return OpExists
+	case "!exists":
+		return OpNotExists
+	}
+	return Error
+}
+
+// CheckAssert accepts assertion and actual value.
+// Found is false if value is missing in response.
+// Returns true if assertion holds.
+func CheckAssert(a Assertion, got []byte, found bool) bool {
+	switch a.Op {
+	case OpExists:
+		return found
+	case OpNotExists:
+		return !found
+	}
+
+	if !found {
+		return a.Op == OpNe || a.Op == OpNotContains
+	}
+
+	switch a.Op {
+	case OpEq, OpNe:
+		eq := bytes.Equal(got, a.Want)
+		if !eq {
+			g, okG := assertNum(RespBody, got)
+			w, okW := assertNum(a.Kind, a.Want)
+			eq = okG && okW && g == w
+		}
+		return eq == (a.Op == OpEq)
+	case OpContains:
+		return bytes.Contains(got, a.Want)
+	case OpNotContains:
+		return !bytes.Contains(got, a.Want)
+	case OpMatches:
+		ok, err := regexp.Match(string(a.Want), got)
+		return err == nil && ok
+	}
+
+	g, okG := assertNum(RespBody, got)
+	w, okW := assertNum(a.Kind, a.Want)
+	if !okG || !okW {
+		return false
+	}
+
+	switch a.Op {
+	case OpLt:
+		return g < w
+	case OpLe:
+		return g <= w
+	case OpGt:
+		return g > w
+	case OpGe:
+		return g >= w
+	}
+	return false
+}
+
+// assertNum converts value to number.
+// For duration value units are allowed, like '300ms' or '1.5s'.
+// Duration is converted to milliseconds.
+func assertNum(kind int, val []byte) (float64, bool) {
+	if len(val) == 0 {
+		return 0, false
+	}
+
+	s := string(val)
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return n, true
+	}
+
+	if kind == RespDuration {
+		if d, err := time.ParseDuration(s); err == nil {
+			return float64(d) / float64(time.Millisecond), true
+		}
+	}
+
+	return 0, false
+}
+
+// nextWord cuts first word from data.
+func nextWord(data *[]byte) []byte {
+	d := *data
+	trimBytes(&d, isSpace)
+
+	end := 0
+	for end < len(d) && !isSpace(d[end]) {
+		end++
+	}
+
+	*data = d[end:]
+	return d[:end]
+}
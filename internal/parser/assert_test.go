@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestParseAssert(t *testing.T) {
+	tests := []struct {
+		input string
+		kinds []int
+		ops   []int
+		wants []string
+		err   bool
+	}{
+		{
+			"\n\tjson:user.role == admin\n\theader:Content-Type contains json\n\tduration < 300ms\n",
+			[]int{RespJSON, RespHeader, RespDuration},
+			[]int{OpEq, OpContains, OpLt},
+			[]string{"admin", "json", "300ms"},
+			false,
+		},
+		{
+			"# comment\nbody matches ^ok$\nheader:ETag exists\nstatus != \"500\"",
+			[]int{RespBody, RespHeader, RespStatus},
+			[]int{OpMatches, OpExists, OpNe},
+			[]string{"^ok$", "", "500"},
+			false,
+		},
+		{"json:id ?? 1", nil, nil, nil, true},
+		{"nop == 1", nil, nil, nil, true},
+		{"size > big", nil, nil, nil, true},
+		{"header: exists", nil, nil, nil, true},
+		{"body matches (", nil, nil, nil, true},
+	}
+
+	for i, tt := range tests {
+		var got []Assertion
+		err := ParseAssert([]byte(tt.input), func(a Assertion) {
+			got = append(got, a)
+		})
+		if (err != nil) != tt.err {
+			t.Fatalf("[%d]: expected error %v, but got %v", i, tt.err, err)
+		}
+		if tt.err {
+			continue
+		}
+		if len(got) != len(tt.kinds) {
+			t.Fatalf("[%d]: expected %d assertions, but got %d", i, len(tt.kinds), len(got))
+		}
+		for j, a := range got {
+			if a.Kind != tt.kinds[j] || a.Op != tt.ops[j] || string(a.Want) != tt.wants[j] {
+				t.Errorf("[%d.%d]: expected %d %d %q, but got %d %d %q",
+					i, j, tt.kinds[j], tt.ops[j], tt.wants[j], a.Kind, a.Op, a.Want)
+			}
+		}
+	}
+}
+
+func BenchmarkParseAssert(b *testing.B) {
+	data := []byte("json:user.role == admin\nduration < 300ms")
+	for b.Loop() {
+		ParseAssert(data, func(Assertion) {})
+	}
+}
+
+func TestCheckAssert(t *testing.T) {
+	tests := []struct {
+		line     string
+		got      string
+		found    bool
+		expected bool
+	}{
+		{"json:role == admin", "admin", true, true},
+		{"json:role == admin", "user", true, false},
+		{"json:id == 42", "42.0", true, true},
+		{"json:role != admin", "", false, true},
+		{"json:role exists", "", false, false},
+		{"json:role !exists", "", false, true},
+		{"header:Content-Type contains json", "application/json", true, true},
+		{"header:Content-Type !contains json", "text/plain", true, true},
+		{"body matches ^ok", "ok fine", true, true},
+		{"duration < 300ms", "120", true, true},
+		{"duration < 1.5s", "2000", true, false},
+		{"size >= 10", "10", true, true},
+		{"status > 299", "200", true, false},
+		{"status <= 299", "abc", true, false},
+	}
+
+	for i, tt := range tests {
+		var a Assertion
+		if err := ParseAssert([]byte(tt.line), func(as Assertion) { a = as }); err != nil {
+			t.Fatalf("[%d]: unexpected error: %v", i, err)
+		}
+		if res := CheckAssert(a, []byte(tt.got), tt.found); res != tt.expected {
+			t.Errorf("[%d]: %q on %q: expected %v, but got %v", i, tt.line, tt.got, tt.expected, res)
+		}
+	}
+}
+
+func BenchmarkCheckAssert(b *testing.B) {
+	a := Assertion{Kind: RespJSON, Op: OpEq, Want: []byte("admin")}
+	got := []byte("admin")
+	for b.Loop() {
+		CheckAssert(a, got, true)
+	}
+}
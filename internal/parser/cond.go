@@ -0,0 +1,149 @@
+// Package parser cond.go parse and check conditions.
+// Condition is a 'If' field, like '{VARIABLE key=env} == prod && {RESPONSE id=0 status} < 400'.
+// Macros are expanded before check, so condition compares plain values.
+package parser
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// condWord is a one word of condition.
+type condWord struct {
+	// val is a word without quotes.
+	val []byte
+
+	// quoted is true if word was in quotes.
+	// Quoted word is never a operator.
+	quoted bool
+}
+
+// ParseIf accepts expanded 'If' field from config.
+// Clauses are joined by '&&' and '||', '&&' binds tighter.
+// Clause is like 'left == right', 'left exists' or a single value.
+// Single value is true if it is not empty, 'false' or '0'.
+// Returns result of condition or error if condition is invalid.
+func ParseIf(cond []byte) (bool, error) {
+	const op = "parser.ParseIf"
+
+	words, err := condWords(cond)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(words) == 0 {
+		return false, fmt.Errorf("%s: empty condition", op)
+	}
+
+	res, all := false, true
+	start := 0
+	for i := 0; i <= len(words); i++ {
+		if i < len(words) && (words[i].quoted || !isJoin(words[i].val)) {
+			continue
+		}
+
+		ok, err := checkClause(words[start:i])
+		if err != nil {
+			return false, fmt.Errorf("%s: %q: %w", op, cond, err)
+		}
+		all = all && ok
+		start = i + 1
+
+		if i == len(words) || string(words[i].val) == "||" {
+			res = res || all
+			all = true
+		}
+	}
+
+	return res, nil
+}
+
+// IsTrue reports whether value is true.
+// Value is true unless it is empty, 'false' or '0'.
+func IsTrue(v []byte) bool {
+	trimBytes(&v, isSpace)
+	return len(v) != 0 && !EqualFold(v, "false") && string(v) != "0"
+}
+
+// IsOff reports whether switch value is off.
+// Value is off if it is 'off', 'false', 'no' or '0', empty value is on.
+func IsOff(v []byte) bool {
+	trimBytes(&v, isSpace)
+	return EqualFold(v, "off") || EqualFold(v, "false") || EqualFold(v, "no") || string(v) == "0"
+}
+
+// checkClause checks one clause of condition.
+func checkClause(words []condWord) (bool, error) {
+	const op = "parser.checkClause"
+
+	switch len(words) {
+	case 0:
+		return false, fmt.Errorf("%s: empty clause", op)
+	case 1:
+		return IsTrue(words[0].val), nil
+	}
+
+	a := Assertion{Kind: RespBody}
+	if words[1].quoted {
+		return false, fmt.Errorf("%s: quoted operator %q", op, words[1].val)
+	}
+	if a.Op = parseOp(words[1].val); a.Op == Error {
+		return false, fmt.Errorf("%s: unknown operator %q", op, words[1].val)
+	}
+
+	got := words[0].val
+	switch a.Op {
+	case OpExists, OpNotExists:
+		if len(words) != 2 {
+			return false, fmt.Errorf("%s: unexpected value after %q", op, words[1].val)
+		}
+		return CheckAssert(a, got, len(got) != 0), nil
+	}
+
+	if len(words) != 3 {
+		return false, fmt.Errorf("%s: clause must be like 'left op right'", op)
+	}
+	a.Want = words[2].val
+	if a.Op == OpMatches {
+		if _, err := regexp.Compile(string(a.Want)); err != nil {
+			return false, fmt.Errorf("%s: invalid regexp %q: %w", op, a.Want, err)
+		}
+	}
+
+	return CheckAssert(a, got, true), nil
+}
+
+// condWords splits condition by spaces.
+// Words in double or single quotes may contain spaces.
+func condWords(cond []byte) ([]condWord, error) {
+	const op = "parser.condWords"
+
+	var words []condWord
+	rest := cond
+	for {
+		trimBytes(&rest, isSpace)
+		if len(rest) == 0 {
+			return words, nil
+		}
+
+		q := rest[0]
+		if q != '"' && q != '\'' {
+			words = append(words, condWord{val: nextWord(&rest)})
+			continue
+		}
+
+		end := 1
+		for end < len(rest) && rest[end] != q {
+			end++
+		}
+		if end == len(rest) {
+			return nil, fmt.Errorf("%s: unclosed quote in %q", op, cond)
+		}
+		words = append(words, condWord{val: rest[1:end], quoted: true})
+		rest = rest[end+1:]
+	}
+}
+
+// isJoin reports whether word joins clauses.
+func isJoin(word []byte) bool {
+	return string(word) == "&&" || string(word) == "||"
+}
@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestParseIf(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+		err      bool
+	}{
+		{"prod == prod", true, false},
+		{"prod != prod", false, false},
+		{"200 < 400", true, false},
+		{"200.0 == 200", true, false},
+		{"admin contains adm", true, false},
+		{"admin !contains adm", false, false},
+		{"tok123 matches ^tok[0-9]+$", true, false},
+		{"tok123 exists", true, false},
+		{"'' !exists", true, false},
+		{`"John Doe" == 'John Doe'`, true, false},
+		{"'a && b' == 'a && b'", true, false},
+		{"true", true, false},
+		{"false", false, false},
+		{"0", false, false},
+		{"''", false, false},
+		{"a == a && b == c", false, false},
+		{"a == b || b == b", true, false},
+		{"a == b && b == b || c == c", true, false},
+		{"a == a || b == c && c == d", true, false},
+		{"", false, true},
+		{"a ==", false, true},
+		{"a is a", false, true},
+		{"a == b c", false, true},
+		{"a exists b", false, true},
+		{"a == a &&", false, true},
+		{"'a == a", false, true},
+		{"a matches (", false, true},
+	}
+
+	for i, tt := range tests {
+		res, err := ParseIf([]byte(tt.input))
+		if tt.err {
+			if err == nil {
+				t.Errorf("[%d]: expected error, but got nil", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d]: unexpected error: %v", i, err)
+			continue
+		}
+
+		if res != tt.expected {
+			t.Errorf("[%d]: %q: expected %v, but got %v", i, tt.input, tt.expected, res)
+		}
+	}
+}
+
+func BenchmarkParseIf(b *testing.B) {
+	cond := []byte("prod == prod && 200 < 400 || 'John Doe' contains John")
+	for b.Loop() {
+		ParseIf(cond)
+	}
+}
+
+func TestIsTrue(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true", true},
+		{" yes ", true},
+		{"1", true},
+		{"", false},
+		{"  ", false},
+		{"FALSE", false},
+		{"0", false},
+	}
+
+	for i, tt := range tests {
+		if res := IsTrue([]byte(tt.input)); res != tt.expected {
+			t.Errorf("[%d]: %q: expected %v, but got %v", i, tt.input, tt.expected, res)
+		}
+	}
+}
+
+func BenchmarkIsTrue(b *testing.B) {
+	v := []byte("false")
+	for b.Loop() {
+		IsTrue(v)
+	}
+}
+
+func TestIsOff(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"off", true},
+		{" OFF ", true},
+		{"false", true},
+		{"no", true},
+		{"0", true},
+		{"", false},
+		{"on", false},
+		{"true", false},
+	}
+
+	for i, tt := range tests {
+		if res := IsOff([]byte(tt.input)); res != tt.expected {
+			t.Errorf("[%d]: %q: expected %v, but got %v", i, tt.input, tt.expected, res)
+		}
+	}
+}
+
+func BenchmarkIsOff(b *testing.B) {
+	v := []byte("off")
+	for b.Loop() {
+		IsOff(v)
+	}
+}
@@ -0,0 +1,127 @@
+// Package parser data.go parse data files for 'DataFile' field.
+// Data file is a CSV with header row, JSON array of objects or NDJSON.
+package parser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Formats of data file.
+const (
+	// DataCSV for CSV file with header row.
+	DataCSV = iota
+
+	// DataJSON for JSON array of objects.
+	DataJSON
+
+	// DataNDJSON for one JSON object per line.
+	DataNDJSON
+)
+
+// DataFormat accepts path of data file.
+// It returns format by file extension or Error if extension is unknown.
+func DataFormat(path string) int {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return DataCSV
+	case ".json":
+		return DataJSON
+	case ".ndjson", ".jsonl":
+		return DataNDJSON
+	}
+	return Error
+}
+
+// ParseData accepts content of data file and its format.
+// Calls yield for each row. Row maps column name to value.
+// JSON strings are unquoted, other JSON values stay raw.
+func ParseData(data []byte, format int, yield func(row map[string][]byte)) error {
+	const op = "parser.ParseData"
+
+	switch format {
+	case DataCSV:
+		return parseCSV(data, yield)
+	case DataJSON:
+		trimBytes(&data, isSpace)
+		var err error
+		if !RangeJSONArray(data, func(i int, obj []byte) {
+			if err != nil {
+				return
+			}
+			row, ok := jsonRow(obj)
+			if !ok {
+				err = fmt.Errorf("%s: element %d is not object", op, i)
+				return
+			}
+			yield(row)
+		}) {
+			return fmt.Errorf("%s: data is not array", op)
+		}
+		return err
+	case DataNDJSON:
+		var err error
+		line := 0
+		RangeByByte(data, '\n', func(start, end int) {
+			line++
+			obj := data[start:end]
+			trimBytes(&obj, isSpace)
+			if err != nil || len(obj) == 0 {
+				return
+			}
+			row, ok := jsonRow(obj)
+			if !ok {
+				err = fmt.Errorf("%s: line %d is not object", op, line)
+				return
+			}
+			yield(row)
+		})
+		return err
+	}
+
+	return fmt.Errorf("%s: unknown format %d", op, format)
+}
+
+// parseCSV parses CSV with header row.
+func parseCSV(data []byte, yield func(row map[string][]byte)) error {
+	const op = "parser.parseCSV"
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+
+	recs, err := r.ReadAll()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(recs) == 0 {
+		return fmt.Errorf("%s: no header row", op)
+	}
+
+	head := recs[0]
+	for _, rec := range recs[1:] {
+		row := make(map[string][]byte, len(head))
+		for i, col := range head {
+			row[col] = []byte(rec[i])
+		}
+		yield(row)
+	}
+	return nil
+}
+
+// jsonRow converts JSON object to row.
+// Returns false if value is not object.
+func jsonRow(obj []byte) (map[string][]byte, bool) {
+	if len(obj) == 0 || obj[0] != '{' || scanValue(obj, 0) != len(obj) {
+		return nil, false
+	}
+
+	row := make(map[string][]byte)
+	rangeMembers(obj, func(key []byte, start, end int) bool {
+		row[string(key)] = unquote(obj[start:end])
+		return true
+	})
+	return row, true
+}
@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestDataFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"rows.csv", DataCSV},
+		{"data/ROWS.CSV", DataCSV},
+		{"rows.json", DataJSON},
+		{"rows.ndjson", DataNDJSON},
+		{"rows.jsonl", DataNDJSON},
+		{"rows.txt", Error},
+		{"rows", Error},
+	}
+
+	for i, tt := range tests {
+		res := DataFormat(tt.input)
+		if res != tt.expected {
+			t.Errorf("[%d]: expected %d, but got %d", i, tt.expected, res)
+		}
+	}
+}
+
+func BenchmarkDataFormat(b *testing.B) {
+	for b.Loop() {
+		DataFormat("data/rows.ndjson")
+	}
+}
+
+func TestParseData(t *testing.T) {
+	tests := []struct {
+		input    string
+		format   int
+		expected []map[string]string
+		err      bool
+	}{
+		{
+			"email,age\na@x.io,20\n\"b,c@x.io\", 30\n",
+			DataCSV,
+			[]map[string]string{
+				{"email": "a@x.io", "age": "20"},
+				{"email": "b,c@x.io", "age": "30"},
+			},
+			false,
+		},
+		{
+			` [{"email": "a@x.io", "age": 20}, {"email": "b@x.io", "tags": ["x"]}] `,
+			DataJSON,
+			[]map[string]string{
+				{"email": "a@x.io", "age": "20"},
+				{"email": "b@x.io", "tags": `["x"]`},
+			},
+			false,
+		},
+		{
+			"{\"email\": \"a@x.io\"}\n\n{\"email\": \"b@x.io\", \"ok\": true}\r\n",
+			DataNDJSON,
+			[]map[string]string{
+				{"email": "a@x.io"},
+				{"email": "b@x.io", "ok": "true"},
+			},
+			false,
+		},
+		{"email,age\na@x.io\n", DataCSV, nil, true},
+		{"", DataCSV, nil, true},
+		{`{"email": "a@x.io"}`, DataJSON, nil, true},
+		{`[{"email": "a@x.io"}, 5]`, DataJSON, nil, true},
+		{"{\"email\": \"a@x.io\"}\n[1]\n", DataNDJSON, nil, true},
+		{"a", Error, nil, true},
+	}
+
+	for i, tt := range tests {
+		var rows []map[string][]byte
+		err := ParseData([]byte(tt.input), tt.format, func(row map[string][]byte) {
+			rows = append(rows, row)
+		})
+		if tt.err {
+			if err == nil {
+				t.Errorf("[%d]: expected error, but got nil", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d]: unexpected error: %v", i, err)
+			continue
+		}
+
+		if len(rows) != len(tt.expected) {
+			t.Errorf("[%d]: expected %d rows, but got %d", i, len(tt.expected), len(rows))
+			continue
+		}
+		for j, row := range rows {
+			if len(row) != len(tt.expected[j]) {
+				t.Errorf("[%d]: row %d: expected %v, but got %q", i, j, tt.expected[j], row)
+				continue
+			}
+			for k, v := range tt.expected[j] {
+				if string(row[k]) != v {
+					t.Errorf("[%d]: row %d: key %q: expected %q, but got %q", i, j, k, v, row[k])
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkParseData(b *testing.B) {
+	data := []byte("{\"email\": \"a@x.io\", \"age\": 20}\n{\"email\": \"b@x.io\", \"age\": 30}\n")
+	for b.Loop() {
+		ParseData(data, DataNDJSON, func(map[string][]byte) {})
+	}
+}
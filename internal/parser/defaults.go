@@ -0,0 +1,297 @@
+// Package parser defaults.go parse '[defaults]' block and apply it to configs.
+// Defaults are merged into config data before instructions are resolved,
+// so macros of defaults are expanded for each config.
+package parser
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Votline/Gurlf"
+	gscan "github.com/Votline/Gurlf/pkg/scanner"
+)
+
+// DefaultsName is a name of defaults block.
+const DefaultsName = "defaults"
+
+// Kinds of merge of default field with config field.
+const (
+	// mergeFill sets default if config field is empty.
+	mergeFill = iota
+
+	// mergeJoin puts default before config field.
+	mergeJoin
+
+	// mergeBase puts default before relative URL.
+	mergeBase
+)
+
+// Defaults is a '[defaults]' block of file.
+// It is applied to 'http' and 'grpc' configs of file and of imported files.
+type Defaults struct {
+	// BaseURL is put before URL which starts with '/'.
+	BaseURL []byte `gurlf:"BaseURL,omitempty"`
+
+	// Headers are put before headers of config, so config headers win.
+	Headers []byte `gurlf:"Headers,omitempty"`
+
+	// Timeout, Certs and Expect are used if config has no own.
+	Timeout []byte `gurlf:"Timeout,omitempty"`
+	Certs   []byte `gurlf:"Certs,omitempty"`
+	Expect  []byte `gurlf:"Expect,omitempty"`
+
+	// Target and ProtoPath are used if gRPC config has no own.
+	Target    []byte `gurlf:"Target,omitempty"`
+	ProtoPath []byte `gurlf:"ProtoPath,omitempty"`
+
+	// Metadata is put before metadata of gRPC config, so config metadata wins.
+	Metadata []byte `gurlf:"Metadata,omitempty"`
+
+	// ClientCert, ClientKey, ClientKeyPass, ServerName and TLSMinVersion
+	// are TLS settings, used if config has no own.
+	ClientCert    []byte `gurlf:"ClientCert,omitempty"`
+	ClientKey     []byte `gurlf:"ClientKey,omitempty"`
+	ClientKeyPass []byte `gurlf:"ClientKeyPass,omitempty"`
+	ServerName    []byte `gurlf:"ServerName,omitempty"`
+	TLSMinVersion []byte `gurlf:"TLSMinVersion,omitempty"`
+
+	// Proxy and NoProxy are proxy settings, used if config has no own.
+	Proxy   []byte `gurlf:"Proxy,omitempty"`
+	NoProxy []byte `gurlf:"NoProxy,omitempty"`
+
+	// Block is a raw block of file, it is written back before configs.
+	// Nil for defaults inherited from importing file.
+	Block []byte
+}
+
+// defaultField is a field of defaults for config type.
+type defaultField struct {
+	key   string
+	kind  int
+	value func(df *Defaults) []byte
+}
+
+// httpDefaults and grpcDefaults are fields of defaults for config types.
+var (
+	httpDefaults = []defaultField{
+		{"URL", mergeBase, func(df *Defaults) []byte { return df.BaseURL }},
+		{"Headers", mergeJoin, func(df *Defaults) []byte { return df.Headers }},
+		{"Timeout", mergeFill, func(df *Defaults) []byte { return df.Timeout }},
+		{"Certs", mergeFill, func(df *Defaults) []byte { return df.Certs }},
+		{"Expect", mergeFill, func(df *Defaults) []byte { return df.Expect }},
+		{"ClientCert", mergeFill, func(df *Defaults) []byte { return df.ClientCert }},
+		{"ClientKey", mergeFill, func(df *Defaults) []byte { return df.ClientKey }},
+		{"ClientKeyPass", mergeFill, func(df *Defaults) []byte { return df.ClientKeyPass }},
+		{"ServerName", mergeFill, func(df *Defaults) []byte { return df.ServerName }},
+		{"TLSMinVersion", mergeFill, func(df *Defaults) []byte { return df.TLSMinVersion }},
+		{"Proxy", mergeFill, func(df *Defaults) []byte { return df.Proxy }},
+		{"NoProxy", mergeFill, func(df *Defaults) []byte { return df.NoProxy }},
+	}
+	grpcDefaults = []defaultField{
+		{"Target", mergeFill, func(df *Defaults) []byte { return df.Target }},
+		{"ProtoPath", mergeFill, func(df *Defaults) []byte { return df.ProtoPath }},
+		{"Metadata", mergeJoin, func(df *Defaults) []byte { return df.Metadata }},
+		{"Timeout", mergeFill, func(df *Defaults) []byte { return df.Timeout }},
+		{"Certs", mergeFill, func(df *Defaults) []byte { return df.Certs }},
+		{"Expect", mergeFill, func(df *Defaults) []byte { return df.Expect }},
+		{"ClientCert", mergeFill, func(df *Defaults) []byte { return df.ClientCert }},
+		{"ClientKey", mergeFill, func(df *Defaults) []byte { return df.ClientKey }},
+		{"ClientKeyPass", mergeFill, func(df *Defaults) []byte { return df.ClientKeyPass }},
+		{"ServerName", mergeFill, func(df *Defaults) []byte { return df.ServerName }},
+		{"TLSMinVersion", mergeFill, func(df *Defaults) []byte { return df.TLSMinVersion }},
+		{"Proxy", mergeFill, func(df *Defaults) []byte { return df.Proxy }},
+		{"NoProxy", mergeFill, func(df *Defaults) []byte { return df.NoProxy }},
+	}
+)
+
+// ownField is a value of config field before defaults.
+type ownField struct {
+	key string
+	val []byte
+}
+
+// ParseDefaults removes '[defaults]' block from start of sData.
+// Block is merged with parent defaults of importing file, own fields win.
+// Returns defaults for configs of file, nil if there are no defaults.
+func ParseDefaults(sData *[]gscan.Data, parent *Defaults) (*Defaults, error) {
+	const op = "parser.ParseDefaults"
+
+	for i, d := range *sData {
+		if i != 0 && string(d.Name) == DefaultsName {
+			return nil, fmt.Errorf("%s: block %q must be first, but it is №[%d]", op, DefaultsName, i)
+		}
+	}
+	if len(*sData) == 0 || string((*sData)[0].Name) != DefaultsName {
+		if parent == nil {
+			return nil, nil
+		}
+		return inherited(parent), nil
+	}
+
+	d := (*sData)[0]
+	var own Defaults
+	if err := gurlf.Unmarshal(d, &own); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	*sData = (*sData)[1:]
+
+	df := inherited(parent)
+	df.Headers = joinField(df.Headers, own.Headers)
+	df.Metadata = joinField(df.Metadata, own.Metadata)
+	override(&df.BaseURL, own.BaseURL)
+	override(&df.Timeout, own.Timeout)
+	override(&df.Certs, own.Certs)
+	override(&df.Expect, own.Expect)
+	override(&df.Target, own.Target)
+	override(&df.ProtoPath, own.ProtoPath)
+	override(&df.ClientCert, own.ClientCert)
+	override(&df.ClientKey, own.ClientKey)
+	override(&df.ClientKeyPass, own.ClientKeyPass)
+	override(&df.ServerName, own.ServerName)
+	override(&df.TLSMinVersion, own.TLSMinVersion)
+	override(&df.Proxy, own.Proxy)
+	override(&df.NoProxy, own.NoProxy)
+
+	// same layout as config, so ends of configs are not shifted
+	df.Block = make([]byte, 0, len(d.RawData)+2*len(d.Name)+7)
+	df.Block = append(df.Block, '[')
+	df.Block = append(df.Block, d.Name...)
+	df.Block = append(df.Block, ']')
+	df.Block = append(df.Block, d.RawData...)
+	df.Block = append(df.Block, '[', '\\')
+	df.Block = append(df.Block, d.Name...)
+	df.Block = append(df.Block, "]\n\n"...)
+
+	return df, nil
+}
+
+// apply returns data of config with defaults and own values of changed fields.
+// Data is copied if changed. Replaced values are blanked,
+// so their instructions are resolved once, in the new value.
+func (df *Defaults) apply(d gscan.Data, tp string) (gscan.Data, []ownField) {
+	var fields []defaultField
+	switch {
+	case df == nil:
+		return d, nil
+	case tp == "http":
+		fields = httpDefaults
+	case tp == "grpc":
+		fields = grpcDefaults
+	default:
+		return d, nil
+	}
+
+	var own []ownField
+	raw, ents := d.RawData, d.Entries
+	for _, f := range fields {
+		def := f.value(df)
+		if len(def) == 0 {
+			continue
+		}
+
+		i := entryIndex(raw, ents, f.key)
+		var cur []byte
+		if i != -1 {
+			cur = raw[ents[i].ValStart:ents[i].ValEnd]
+		}
+
+		val := mergeField(f.kind, def, cur)
+		if val == nil {
+			continue
+		}
+
+		if own == nil {
+			raw = append(make([]byte, 0, len(raw)+len(val)+64), raw...)
+			ents = append(make([]gscan.Entry, 0, len(ents)+len(fields)), ents...)
+			if i != -1 {
+				cur = raw[ents[i].ValStart:ents[i].ValEnd]
+			}
+		}
+		own = append(own, ownField{key: f.key, val: bytes.Clone(cur)})
+
+		if i == -1 {
+			ents = append(ents, gscan.Entry{KeyStart: len(raw), KeyEnd: len(raw) + len(f.key)})
+			i = len(ents) - 1
+			raw = append(raw, f.key...)
+			raw = append(raw, ':')
+		} else {
+			for j := range cur {
+				cur[j] = ' '
+			}
+		}
+
+		ents[i].ValStart = len(raw)
+		raw = append(raw, val...)
+		ents[i].ValEnd = len(raw)
+		raw = append(raw, '\n')
+	}
+
+	if own == nil {
+		return d, nil
+	}
+	return gscan.Data{Name: d.Name, RawData: raw, Entries: ents}, own
+}
+
+// mergeField returns value of config field with default.
+// Returns nil if field is not changed.
+func mergeField(kind int, def, cur []byte) []byte {
+	if len(cur) == 0 {
+		return def
+	}
+
+	switch kind {
+	case mergeJoin:
+		return joinField(def, cur)
+	case mergeBase:
+		if cur[0] != '/' {
+			return nil
+		}
+		base := bytes.TrimRight(def, "/")
+		val := make([]byte, 0, len(base)+len(cur))
+		val = append(val, base...)
+		return append(val, cur...)
+	}
+	return nil
+}
+
+// joinField joins two values of field by new line.
+func joinField(a, b []byte) []byte {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+
+	val := make([]byte, 0, len(a)+len(b)+1)
+	val = append(val, a...)
+	val = append(val, '\n')
+	return append(val, b...)
+}
+
+// inherited returns copy of parent defaults without block.
+func inherited(parent *Defaults) *Defaults {
+	df := &Defaults{}
+	if parent != nil {
+		*df = *parent
+		df.Block = nil
+	}
+	return df
+}
+
+// override sets field to own value if it is not empty.
+func override(field *[]byte, own []byte) {
+	if len(own) != 0 {
+		*field = own
+	}
+}
+
+// entryIndex returns index of entry with key or -1.
+func entryIndex(raw []byte, ents []gscan.Entry, key string) int {
+	for i, e := range ents {
+		if string(raw[e.KeyStart:e.KeyEnd]) == key {
+			return i
+		}
+	}
+	return -1
+}
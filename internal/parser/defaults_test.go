@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Votline/Gurl-cli/internal/config"
+
+	"github.com/Votline/Gurlf"
+	"go.uber.org/zap"
+)
+
+var defRaw = []byte(`[defaults]
+BaseURL:http://localhost:8080/
+Headers:Authorization: Bearer {VARIABLE key=token}
+Expect:2xx
+[\defaults]
+
+[me]
+URL:/me
+Headers:X-Id: 1
+Type:http
+[\me]
+
+[ext]
+URL:http://example.com
+Expect:404
+Type:http
+[\ext]`)
+
+func TestParseDefaults(t *testing.T) {
+	d, _ := gurlf.Scan(defRaw)
+	defs, err := ParseDefaults(&d, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(d) != 2 {
+		t.Fatalf("expected %d configs, but got %d", 2, len(d))
+	}
+	if string(defs.Expect) != "2xx" {
+		t.Errorf("expected %q, but got %q", "2xx", defs.Expect)
+	}
+	if block := defRaw[:bytes.Index(defRaw, []byte("[me]"))]; string(defs.Block) != string(block) {
+		t.Errorf("expected block %q, but got %q", block, defs.Block)
+	}
+
+	child, _ := gurlf.Scan([]byte("[defaults]\nHeaders:X-Id: 2\nExpect:200\n[\\defaults]\n"))
+	merged, err := ParseDefaults(&child, defs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(merged.Expect) != "200" || string(merged.BaseURL) != "http://localhost:8080/" {
+		t.Errorf("unexpected merged defaults: %q, %q", merged.Expect, merged.BaseURL)
+	}
+	if string(merged.Headers) != "Authorization: Bearer {VARIABLE key=token}\nX-Id: 2" {
+		t.Errorf("unexpected merged headers: %q", merged.Headers)
+	}
+
+	none, _ := gurlf.Scan(defRaw[bytes.Index(defRaw, []byte("[me]")):])
+	if inh, _ := ParseDefaults(&none, defs); inh == nil || inh.Block != nil {
+		t.Errorf("expected inherited defaults without block, but got %v", inh)
+	}
+	if res, _ := ParseDefaults(&none, nil); res != nil {
+		t.Errorf("expected nil, but got %v", res)
+	}
+
+	late, _ := gurlf.Scan(append(defRaw[bytes.Index(defRaw, []byte("[me]")):], "\n[defaults]\nExpect:200\n[\\defaults]"...))
+	if _, err := ParseDefaults(&late, nil); err == nil {
+		t.Errorf("expected error, but got nil")
+	}
+}
+
+func BenchmarkParseDefaults(b *testing.B) {
+	d, _ := gurlf.Scan(defRaw)
+	for b.Loop() {
+		cp := d
+		ParseDefaults(&cp, nil)
+	}
+}
+
+func TestParseStreamDefaults(t *testing.T) {
+	config.Init()
+	log := zap.NewNop()
+
+	d, _ := gurlf.Scan(defRaw)
+	defs, _ := ParseDefaults(&d, nil)
+
+	tests := []struct {
+		url, headers, expect          string
+		ownURL, ownHeaders, ownExpect string
+	}{
+		{"http://localhost:8080/me", "Authorization: Bearer {VARIABLE key=token}\nX-Id: 1", "2xx",
+			"/me", "X-Id: 1", ""},
+		{"http://example.com", "Authorization: Bearer {VARIABLE key=token}", "404",
+			"http://example.com", "", "404"},
+	}
+
+	i := 0
+	if err := ParseStream(&d, defs, func(c config.Config) {
+		defer c.Release()
+		tt := tests[i]
+		i++
+
+		if string(c.GetRaw("URL")) != tt.url {
+			t.Errorf("[%d]: expected %q, but got %q", i, tt.url, c.GetRaw("URL"))
+		}
+		if string(c.GetRaw("Headers")) != tt.headers {
+			t.Errorf("[%d]: expected %q, but got %q", i, tt.headers, c.GetRaw("Headers"))
+		}
+		if string(c.GetExpect()) != tt.expect {
+			t.Errorf("[%d]: expected %q, but got %q", i, tt.expect, c.GetExpect())
+		}
+		if c.GetDepsLen() != 1 {
+			t.Errorf("[%d]: expected %d dependency, but got %d", i, 1, c.GetDepsLen())
+		}
+		c.RangeDeps(func(dep config.Dependency) {
+			if v := c.GetRaw(dep.Key)[dep.Start:dep.End]; string(v) != "{VARIABLE key=token}" {
+				t.Errorf("[%d]: unexpected dependency %q", i, v)
+			}
+		})
+
+		c.StripDefaults()
+		if string(c.GetRaw("URL")) != tt.ownURL {
+			t.Errorf("[%d]: expected own %q, but got %q", i, tt.ownURL, c.GetRaw("URL"))
+		}
+		if string(c.GetRaw("Headers")) != tt.ownHeaders {
+			t.Errorf("[%d]: expected own %q, but got %q", i, tt.ownHeaders, c.GetRaw("Headers"))
+		}
+		if string(c.GetExpect()) != tt.ownExpect {
+			t.Errorf("[%d]: expected own %q, but got %q", i, tt.ownExpect, c.GetExpect())
+		}
+	}, log); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func BenchmarkParseStreamDefaults(b *testing.B) {
+	config.Init()
+	d, _ := gurlf.Scan(defRaw)
+	defs, _ := ParseDefaults(&d, nil)
+	log := zap.NewNop()
+
+	for b.Loop() {
+		if err := ParseStream(&d, defs, yield, log); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
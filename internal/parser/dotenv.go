@@ -0,0 +1,295 @@
+// Package parser dotenv.go read and write env files.
+// Comments, blank lines and order of keys are kept on rewrite.
+// Values may be quoted, multi-line and have 'export' prefix.
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// dotenv is a parsed env file.
+type dotenv struct {
+	lines []envLine
+}
+
+// envLine is a line of env file.
+// Quoted value may take several lines of file.
+type envLine struct {
+	// raw is a text of line with new line.
+	raw []byte
+
+	// key is a key of value, nil for comments, blank and invalid lines.
+	key []byte
+
+	// val is a value without quotes and escapes.
+	val []byte
+
+	// export is true if line starts with 'export'.
+	export bool
+
+	// comment is a text after value, like ' # comment'.
+	comment []byte
+}
+
+// parseDotenv accepts content of env file.
+// Every byte of content is kept in lines.
+func parseDotenv(data []byte) *dotenv {
+	env := &dotenv{}
+	for len(data) != 0 {
+		line, n := parseEnvLine(data)
+		env.lines = append(env.lines, line)
+		data = data[n:]
+	}
+	return env
+}
+
+// set updates all lines with key or appends new line.
+// Prefix 'export' and comment of line are kept.
+func (env *dotenv) set(key string, val []byte) {
+	found := false
+	for i := range env.lines {
+		l := &env.lines[i]
+		if l.key == nil || string(l.key) != key {
+			continue
+		}
+		found = true
+		l.val = val
+		l.raw = formatEnvLine(l)
+	}
+
+	if !found {
+		l := envLine{key: []byte(key), val: val}
+		l.raw = formatEnvLine(&l)
+		env.lines = append(env.lines, l)
+	}
+}
+
+// marshal returns content of env file.
+func (env *dotenv) marshal() []byte {
+	var buf []byte
+	for _, l := range env.lines {
+		if len(buf) != 0 && buf[len(buf)-1] != '\n' {
+			buf = append(buf, '\n')
+		}
+		buf = append(buf, l.raw...)
+	}
+	return buf
+}
+
+// parseEnvLine accepts content of env file from start of line.
+// Returns first line and its length.
+// Line without 'key=' is kept as is.
+func parseEnvLine(data []byte) (envLine, int) {
+	end := lineEnd(data, 0)
+	line := envLine{raw: data[:end]}
+
+	i := skipBlank(data, 0)
+	if bytes.HasPrefix(data[i:], []byte("export")) && i+6 < len(data) && isBlank(data[i+6]) {
+		line.export = true
+		i = skipBlank(data, i+6)
+	}
+
+	ks := i
+	for i < len(data) && isEnvKey(data[i]) {
+		i++
+	}
+	ke := i
+	i = skipBlank(data, i)
+	if ks == ke || i >= end || data[i] != '=' {
+		return envLine{raw: data[:end]}, end
+	}
+	line.key = data[ks:ke]
+
+	vs := skipBlank(data, i+1)
+	if vs < len(data) && (data[vs] == '"' || data[vs] == '\'') {
+		var n int
+		line.val, n = unquoteEnv(data[vs:])
+		i = vs + n
+		end = lineEnd(data, i)
+		line.raw = data[:end]
+		line.comment = bytes.TrimRight(data[i:end], "\r\n")
+		return line, end
+	}
+
+	val := bytes.TrimRight(data[i+1:end], "\r\n")
+	for j := 1; j < len(val); j++ {
+		if val[j] == '#' && isBlank(val[j-1]) {
+			for j > 0 && isBlank(val[j-1]) {
+				j--
+			}
+			line.comment = val[j:]
+			val = val[:j]
+			break
+		}
+	}
+	line.val = bytes.Trim(val, " \t")
+	return line, end
+}
+
+// unquoteEnv accepts value which starts with quote.
+// Returns value and length with quotes.
+// Escapes are handled in double quotes only.
+// Value without closing quote goes to end of data.
+func unquoteEnv(data []byte) ([]byte, int) {
+	q := data[0]
+	if q == '\'' {
+		end := bytes.IndexByte(data[1:], q)
+		if end == -1 {
+			return data[1:], len(data)
+		}
+		return data[1 : end+1], end + 2
+	}
+
+	var val []byte
+	start := 1
+	for i := 1; i < len(data); i++ {
+		switch data[i] {
+		case '"':
+			if val == nil {
+				return data[1:i], i + 1
+			}
+			return append(val, data[start:i]...), i + 1
+		case '\\':
+			if i+1 == len(data) {
+				continue
+			}
+			if val == nil {
+				val = make([]byte, 0, len(data))
+			}
+			val = append(val, data[start:i]...)
+			val = append(val, unescapeEnv(data[i+1])...)
+			i++
+			start = i + 1
+		}
+	}
+
+	if val == nil {
+		return data[1:], len(data)
+	}
+	return append(val, data[start:]...), len(data)
+}
+
+// unescapeEnv returns value of escaped byte.
+// Unknown escape is kept with backslash.
+func unescapeEnv(b byte) []byte {
+	switch b {
+	case 'n':
+		return []byte{'\n'}
+	case 'r':
+		return []byte{'\r'}
+	case 't':
+		return []byte{'\t'}
+	case '"', '\\', '$':
+		return []byte{b}
+	}
+	return []byte{'\\', b}
+}
+
+// formatEnvLine returns text of line with key and value.
+// Value is quoted if it has spaces, quotes or special bytes.
+func formatEnvLine(l *envLine) []byte {
+	buf := make([]byte, 0, len(l.key)+len(l.val)+len(l.comment)+16)
+	if l.export {
+		buf = append(buf, "export "...)
+	}
+	buf = append(buf, l.key...)
+	buf = append(buf, '=')
+
+	if bytes.ContainsAny(l.val, " \t\r\n\"'\\#$`") {
+		buf = append(buf, '"')
+		for _, b := range l.val {
+			switch b {
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '"', '\\', '$':
+				buf = append(buf, '\\', b)
+			default:
+				buf = append(buf, b)
+			}
+		}
+		buf = append(buf, '"')
+	} else {
+		buf = append(buf, l.val...)
+	}
+
+	buf = append(buf, l.comment...)
+	return append(buf, '\n')
+}
+
+// WriteFileAtomic writes data to temporary file and renames it to path.
+// So file is never half written. Mode of existing file is kept,
+// perm is used for new file, like in os.WriteFile.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	const op = "parser.WriteFileAtomic"
+
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	mode := perm
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("%s: create temp file: %w", op, err)
+	}
+	tmp := f.Name()
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("%s: write %q: %w", op, tmp, err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("%s: sync %q: %w", op, tmp, err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("%s: close %q: %w", op, tmp, err)
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("%s: chmod %q: %w", op, tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("%s: rename %q: %w", op, tmp, err)
+	}
+	return nil
+}
+
+// lineEnd returns index after new line from idx or length of data.
+func lineEnd(data []byte, idx int) int {
+	end := bytes.IndexByte(data[idx:], '\n')
+	if end == -1 {
+		return len(data)
+	}
+	return idx + end + 1
+}
+
+// skipBlank returns index of first not blank byte from idx.
+func skipBlank(data []byte, idx int) int {
+	for idx < len(data) && isBlank(data[idx]) {
+		idx++
+	}
+	return idx
+}
+
+// isBlank checks if byte is space or tab.
+func isBlank(b byte) bool {
+	return b == ' ' || b == '\t'
+}
+
+// isEnvKey checks if byte can be in key of env file.
+func isEnvKey(b byte) bool {
+	return b == '_' || b == '.' || b == '-' ||
+		'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9'
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Votline/Gurlf"
+)
+
+var envRaw = []byte(`# tokens
+export TOKEN=old # rotated daily
+REFRESH_TOKEN='keep'
+
+CERT="line1
+line2"
+not a pair
+LAST=1`)
+
+func TestDotenvSet(t *testing.T) {
+	if string(parseDotenv(envRaw).marshal()) != string(envRaw) {
+		t.Fatalf("expected unchanged content, but got %q", parseDotenv(envRaw).marshal())
+	}
+
+	env := parseDotenv(envRaw)
+	env.set("TOKEN", []byte("new"))
+	env.set("CERT", []byte("a \"b\"\nc"))
+	env.set("NEW", []byte("$x y"))
+
+	expected := `# tokens
+export TOKEN=new # rotated daily
+REFRESH_TOKEN='keep'
+
+CERT="a \"b\"\nc"
+not a pair
+LAST=1
+NEW="\$x y"
+`
+	res := env.marshal()
+	if string(res) != expected {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expected, res)
+	}
+
+	tests := []struct {
+		key, val string
+	}{
+		{"TOKEN", "new"},
+		{"REFRESH_TOKEN", "keep"},
+		{"CERT", "a \"b\"\nc"},
+		{"LAST", "1"},
+		{"NEW", "$x y"},
+	}
+	for i, tt := range tests {
+		var val []byte
+		SearchKey(res, []byte(tt.key), &val)
+		if string(val) != tt.val {
+			t.Errorf("[%d]: %q: expected %q, but got %q", i, tt.key, tt.val, val)
+		}
+	}
+}
+
+func BenchmarkDotenvSet(b *testing.B) {
+	for b.Loop() {
+		env := parseDotenv(envRaw)
+		env.set("TOKEN", []byte("new"))
+		env.marshal()
+	}
+}
+
+func TestApplyEnvs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, envRaw, 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	d, _ := gurlf.Scan([]byte("[" + path + "]\nTOKEN:new\nNEW:1\n[\\" + path + "]"))
+	if err := ApplyEnvs(d); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res, _ := os.ReadFile(path)
+	var val []byte
+	if SearchKey(res, []byte("TOKEN"), &val); string(val) != "new" {
+		t.Errorf("expected %q, but got %q", "new", val)
+	}
+	if SearchKey(res, []byte("REFRESH_TOKEN"), &val); string(val) != "keep" {
+		t.Errorf("expected %q, but got %q", "keep", val)
+	}
+	if SearchKey(res, []byte("NEW"), &val); string(val) != "1" {
+		t.Errorf("expected %q, but got %q", "1", val)
+	}
+
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode %v, but got %v", os.FileMode(0o600), info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("expected only env file, but got %d files", len(entries))
+	}
+}
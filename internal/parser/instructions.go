@@ -206,75 +206,16 @@ func ParseEnv(from, key, def *[]byte) {
 	nextVal(def, &inst)
 }
 
-// SearchKey accepts data, key and buffer.
-// It find key in data and update buffer.
+// SearchKey accepts content of env file, key and buffer.
+// It find value of exact key in data and update buffer.
+// Buffer is nil if there is no key.
 func SearchKey(data, key []byte, val *[]byte) {
-	if len(data) == 0 {
-		*val = nil
-		return
-	}
-
-	idx := bytes.Index(data, key)
-	if idx == -1 {
-		*val = nil
-		return
-	}
-	idx += len(key)
-
-	for idx < len(data) && isSpace(data[idx]) {
-		idx++
-	}
-	if idx == len(data) {
-		*val = nil
-		return
-	}
-
-	start := bytes.IndexByte(data[idx:], '=')
-	if start == -1 {
-		*val = nil
-		return
-	}
-	start += idx + 1 // skip '='
-
-	var end int
-
-	if data[start] == '"' {
-		start++ // skip '"'
-
-		seacrhFrom := start
-		for {
-			relIdx := bytes.IndexByte(data[seacrhFrom:], '"')
-			if relIdx == -1 {
-				end = len(data)
-				break
-			}
-
-			absIdx := seacrhFrom + relIdx
-
-			if absIdx > start {
-				if absIdx-1 >= 0 && data[absIdx-1] == '\\' {
-					if absIdx-2 >= 0 && data[absIdx-2] != '\\' {
-						seacrhFrom = absIdx + 1
-						continue
-					}
-				}
-			}
-
-			end = absIdx
-			break
-		}
-	} else {
-		relEnd := bytes.IndexByte(data[start:], '\n')
-		if relEnd == -1 {
-			end = len(data)
-		} else {
-			end = start + relEnd
+	*val = nil
+	for len(data) != 0 {
+		line, n := parseEnvLine(data)
+		if line.key != nil && bytes.Equal(line.key, key) {
+			*val = line.val
 		}
+		data = data[n:]
 	}
-
-	for end > start && isSpace(data[end-1]) {
-		end--
-	}
-
-	*val = data[start:end]
 }
@@ -156,14 +156,14 @@ func TestSearchKey(t *testing.T) {
 			name:     "Escaped quotes in multiline",
 			data:     []byte("KEY=\"first line\nsecond \\\"quoted\\\" line\""),
 			key:      []byte("KEY"),
-			expected: []byte("first line\nsecond \\\"quoted\\\" line"),
+			expected: []byte("first line\nsecond \"quoted\" line"),
 		},
 
 		{
 			name:     "Key as suffix",
 			data:     []byte("USER_ID=100\nID=200"),
 			key:      []byte("ID"),
-			expected: []byte("100"),
+			expected: []byte("200"),
 		},
 		{
 			name:     "Start equals end (empty quoted)",
@@ -182,7 +182,7 @@ func TestSearchKey(t *testing.T) {
 			name:     "Quote with backslash at the end",
 			data:     []byte(`KEY="value with slash \\"`),
 			key:      []byte("KEY"),
-			expected: []byte(`value with slash \\`),
+			expected: []byte(`value with slash \`),
 		},
 		{
 			name:     "Unclosed quote goes to EOF",
@@ -190,6 +190,43 @@ func TestSearchKey(t *testing.T) {
 			key:      []byte("KEY"),
 			expected: []byte("no closing quote here"),
 		},
+
+		{
+			name:     "Key as prefix",
+			data:     []byte("TOKEN_TYPE=bearer\nREFRESH_TOKEN=r\nTOKEN=t"),
+			key:      []byte("TOKEN"),
+			expected: []byte("t"),
+		},
+		{
+			name:     "Missing key",
+			data:     []byte("REFRESH_TOKEN=r"),
+			key:      []byte("TOKEN"),
+			expected: nil,
+		},
+		{
+			name:     "Comments and export",
+			data:     []byte("# TOKEN=old\nexport TOKEN = new # comment\n"),
+			key:      []byte("TOKEN"),
+			expected: []byte("new"),
+		},
+		{
+			name:     "Single quotes without escapes",
+			data:     []byte(`KEY='a\n"b" # c'`),
+			key:      []byte("KEY"),
+			expected: []byte(`a\n"b" # c`),
+		},
+		{
+			name:     "Escapes in double quotes",
+			data:     []byte(`KEY="a\nb\t\$c\q"`),
+			key:      []byte("KEY"),
+			expected: []byte("a\nb\t$c\\q"),
+		},
+		{
+			name:     "Last value wins",
+			data:     []byte("KEY=1\r\nKEY=2\r\n"),
+			key:      []byte("KEY"),
+			expected: []byte("2"),
+		},
 	}
 
 	for i, tt := range tests {
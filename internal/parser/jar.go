@@ -0,0 +1,238 @@
+// Package parser jar.go parse cookies of jar by RFC 6265.
+// Cookie is scoped by domain and path of request and has expiry.
+// Jar file has Netscape format, like files of curl '--cookie-jar'.
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jarHttpOnly is a prefix of domain for HttpOnly cookie in jar file.
+const jarHttpOnly = "#HttpOnly_"
+
+// JarCookie is a cookie of jar.
+type JarCookie struct {
+	// Name and Value of cookie.
+	Name  string
+	Value string
+
+	// Domain is a host or domain of cookie without leading dot.
+	Domain string
+
+	// Path is a path prefix of requests which get cookie.
+	Path string
+
+	// HostOnly is true if cookie has no 'Domain' attribute.
+	// It is sent to the same host only, without subdomains.
+	HostOnly bool
+
+	// Secure cookie is sent over 'https' and 'wss' only.
+	Secure bool
+
+	// HttpOnly is kept for jar file only.
+	HttpOnly bool
+
+	// Expires is a time of expiry, zero for session cookie.
+	Expires time.Time
+}
+
+// ParseJarCookie accepts url of request, cookie of response and current time.
+// Returns cookie scoped by domain and path of request.
+// Returns false if domain of cookie doesn't match host of request.
+// Cookie with 'Max-Age<=0' or past 'Expires' is expired, it deletes same cookie of jar.
+func ParseJarCookie(u *url.URL, c *http.Cookie, now time.Time) (JarCookie, bool) {
+	host, ok := JarHost(u)
+	if !ok || c.Name == "" {
+		return JarCookie{}, false
+	}
+
+	jc := JarCookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+	}
+	if jc.Path == "" || jc.Path[0] != '/' {
+		jc.Path = defaultPath(u.Path)
+	}
+
+	if jc.Domain, jc.HostOnly, ok = cookieDomain(host, c.Domain); !ok {
+		return JarCookie{}, false
+	}
+
+	switch {
+	case c.MaxAge < 0:
+		jc.Expires = time.Unix(0, 0)
+	case c.MaxAge > 0:
+		jc.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+	case !c.Expires.IsZero():
+		jc.Expires = c.Expires
+	}
+
+	return jc, true
+}
+
+// Key returns key of cookie in jar.
+// Cookie with the same key replaces old one.
+func (jc *JarCookie) Key() string {
+	return jc.Domain + ";" + jc.Path + ";" + jc.Name
+}
+
+// Expired reports whether cookie is expired at time.
+// Session cookie is never expired.
+func (jc *JarCookie) Expired(now time.Time) bool {
+	return !jc.Expires.IsZero() && !jc.Expires.After(now)
+}
+
+// Match reports whether cookie is sent with request to url.
+func (jc *JarCookie) Match(u *url.URL) bool {
+	host, ok := JarHost(u)
+	if !ok {
+		return false
+	}
+	if jc.Secure && u.Scheme != "https" && u.Scheme != "wss" {
+		return false
+	}
+
+	if host != jc.Domain && (jc.HostOnly || net.ParseIP(host) != nil ||
+		!strings.HasSuffix(host, "."+jc.Domain)) {
+		return false
+	}
+
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	if path == jc.Path {
+		return true
+	}
+	return strings.HasPrefix(path, jc.Path) &&
+		(jc.Path[len(jc.Path)-1] == '/' || path[len(jc.Path)] == '/')
+}
+
+// JarHost returns host of url in lower case, without port and trailing dot.
+func JarHost(u *url.URL) (string, bool) {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	return host, host != ""
+}
+
+// ParseJarFile accepts content of jar file in Netscape format.
+// Returns cookies of file, expired cookies are kept.
+// Line is 'domain, subdomains, path, secure, expires, name, value' separated by tab.
+func ParseJarFile(data []byte) ([]JarCookie, error) {
+	const op = "parser.ParseJarFile"
+
+	var cookies []JarCookie
+	for n := 1; len(data) != 0; n++ {
+		var line []byte
+		line, data, _ = bytes.Cut(data, []byte("\n"))
+		line = bytes.TrimRight(line, "\r")
+
+		var jc JarCookie
+		if rest, ok := bytes.CutPrefix(line, []byte(jarHttpOnly)); ok {
+			jc.HttpOnly = true
+			line = rest
+		}
+		if len(bytes.TrimSpace(line)) == 0 || line[0] == '#' {
+			continue
+		}
+
+		fields := strings.Split(string(line), "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("%s: line %d: expected 7 fields, but got %d", op, n, len(fields))
+		}
+
+		exp, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: line %d: invalid expires %q", op, n, fields[4])
+		}
+		if exp != 0 {
+			jc.Expires = time.Unix(exp, 0)
+		}
+
+		if fields[0] == "" || fields[2] == "" || fields[5] == "" {
+			return nil, fmt.Errorf("%s: line %d: empty domain, path or name", op, n)
+		}
+		jc.Domain = strings.ToLower(strings.TrimPrefix(fields[0], "."))
+		jc.HostOnly = fields[0][0] != '.' && !strings.EqualFold(fields[1], "TRUE")
+		jc.Path = fields[2]
+		jc.Secure = strings.EqualFold(fields[3], "TRUE")
+		jc.Name = fields[5]
+		jc.Value = fields[6]
+
+		cookies = append(cookies, jc)
+	}
+
+	return cookies, nil
+}
+
+// AppendJarCookie appends line of jar file for cookie to buffer.
+func AppendJarCookie(buf []byte, jc *JarCookie) []byte {
+	if jc.HttpOnly {
+		buf = append(buf, jarHttpOnly...)
+	}
+	if !jc.HostOnly {
+		buf = append(buf, '.')
+	}
+	buf = append(buf, jc.Domain...)
+	buf = append(buf, '\t')
+	buf = appendJarBool(buf, !jc.HostOnly)
+	buf = append(buf, jc.Path...)
+	buf = append(buf, '\t')
+	buf = appendJarBool(buf, jc.Secure)
+	if jc.Expires.IsZero() {
+		buf = append(buf, '0')
+	} else {
+		buf = strconv.AppendInt(buf, jc.Expires.Unix(), 10)
+	}
+	buf = append(buf, '\t')
+	buf = append(buf, jc.Name...)
+	buf = append(buf, '\t')
+	buf = append(buf, jc.Value...)
+	return append(buf, '\n')
+}
+
+// appendJarBool appends 'TRUE' or 'FALSE' field with tab.
+func appendJarBool(buf []byte, v bool) []byte {
+	if v {
+		return append(buf, "TRUE\t"...)
+	}
+	return append(buf, "FALSE\t"...)
+}
+
+// cookieDomain returns domain of cookie for host of request.
+// Without 'Domain' attribute cookie is host only.
+// Domain must be host or its parent domain with dot, IP host has no parents.
+func cookieDomain(host, attr string) (string, bool, bool) {
+	if attr == "" {
+		return host, true, true
+	}
+
+	domain := strings.TrimSuffix(strings.ToLower(strings.TrimPrefix(attr, ".")), ".")
+	if domain == host {
+		return domain, false, true
+	}
+	if net.ParseIP(host) != nil || !strings.Contains(domain, ".") ||
+		!strings.HasSuffix(host, "."+domain) {
+		return "", false, false
+	}
+	return domain, false, true
+}
+
+// defaultPath returns default path of cookie for path of request.
+// It is a directory of path, like '/api' for '/api/login'.
+func defaultPath(path string) string {
+	i := strings.LastIndexByte(path, '/')
+	if i <= 0 || path[0] != '/' {
+		return "/"
+	}
+	return path[:i]
+}
@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseJarCookie(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	u, _ := url.Parse("http://api.example.com:8080/v1/login")
+
+	tests := []struct {
+		input    *http.Cookie
+		ok       bool
+		domain   string
+		path     string
+		hostOnly bool
+		expired  bool
+	}{
+		{&http.Cookie{Name: "a", Value: "1"}, true, "api.example.com", "/v1", true, false},
+		{&http.Cookie{Name: "a", Domain: ".Example.com", Path: "/"}, true, "example.com", "/", false, false},
+		{&http.Cookie{Name: "a", Domain: "api.example.com", Path: "/v1/"}, true, "api.example.com", "/v1/", false, false},
+		{&http.Cookie{Name: "a", Domain: "other.com"}, false, "", "", false, false},
+		{&http.Cookie{Name: "a", Domain: "com"}, false, "", "", false, false},
+		{&http.Cookie{Name: "a", Path: "rel"}, true, "api.example.com", "/v1", true, false},
+		{&http.Cookie{Name: "a", MaxAge: -1}, true, "api.example.com", "/v1", true, true},
+		{&http.Cookie{Name: "a", Expires: now.Add(-time.Hour)}, true, "api.example.com", "/v1", true, true},
+		{&http.Cookie{Name: "a", MaxAge: 60, Expires: now.Add(-time.Hour)}, true, "api.example.com", "/v1", true, false},
+	}
+
+	for i, tt := range tests {
+		jc, ok := ParseJarCookie(u, tt.input, now)
+		if ok != tt.ok {
+			t.Errorf("[%d]: expected ok %v, but got %v", i, tt.ok, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if jc.Domain != tt.domain || jc.Path != tt.path || jc.HostOnly != tt.hostOnly {
+			t.Errorf("[%d]: expected %q %q %v, but got %q %q %v",
+				i, tt.domain, tt.path, tt.hostOnly, jc.Domain, jc.Path, jc.HostOnly)
+		}
+		if jc.Expired(now) != tt.expired {
+			t.Errorf("[%d]: expected expired %v, but got %v", i, tt.expired, jc.Expired(now))
+		}
+	}
+}
+
+func BenchmarkParseJarCookie(b *testing.B) {
+	now := time.Now()
+	u, _ := url.Parse("http://api.example.com/v1/login")
+	c := &http.Cookie{Name: "a", Value: "1", Domain: "example.com", MaxAge: 60}
+	for b.Loop() {
+		ParseJarCookie(u, c, now)
+	}
+}
+
+func TestJarCookieMatch(t *testing.T) {
+	host := JarCookie{Name: "a", Domain: "example.com", Path: "/api", HostOnly: true}
+	domain := JarCookie{Name: "a", Domain: "example.com", Path: "/", Secure: true}
+
+	tests := []struct {
+		jc       *JarCookie
+		url      string
+		expected bool
+	}{
+		{&host, "http://example.com/api", true},
+		{&host, "http://EXAMPLE.com:80/api/users", true},
+		{&host, "http://example.com/apiv2", false},
+		{&host, "http://example.com/", false},
+		{&host, "http://sub.example.com/api", false},
+		{&domain, "https://sub.example.com/x", true},
+		{&domain, "wss://example.com", true},
+		{&domain, "http://example.com/x", false},
+		{&domain, "https://notexample.com/x", false},
+	}
+
+	for i, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		if res := tt.jc.Match(u); res != tt.expected {
+			t.Errorf("[%d]: %q: expected %v, but got %v", i, tt.url, tt.expected, res)
+		}
+	}
+}
+
+func BenchmarkJarCookieMatch(b *testing.B) {
+	jc := JarCookie{Name: "a", Domain: "example.com", Path: "/api"}
+	u, _ := url.Parse("https://sub.example.com/api/users")
+	for b.Loop() {
+		jc.Match(u)
+	}
+}
+
+func TestParseJarFile(t *testing.T) {
+	cookies := []JarCookie{
+		{Name: "sid", Value: "1", Domain: "example.com", Path: "/", HostOnly: true, HttpOnly: true},
+		{Name: "pref", Value: "a b", Domain: "example.com", Path: "/api", Secure: true, Expires: time.Unix(1700000000, 0)},
+	}
+
+	var data []byte
+	data = append(data, "# Netscape HTTP Cookie File\n\n"...)
+	for i := range cookies {
+		data = AppendJarCookie(data, &cookies[i])
+	}
+
+	expected := "# Netscape HTTP Cookie File\n\n" +
+		"#HttpOnly_example.com\tFALSE\t/\tFALSE\t0\tsid\t1\n" +
+		".example.com\tTRUE\t/api\tTRUE\t1700000000\tpref\ta b\n"
+	if string(data) != expected {
+		t.Fatalf("expected %q, but got %q", expected, data)
+	}
+
+	res, err := ParseJarFile(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res) != len(cookies) {
+		t.Fatalf("expected %d cookies, but got %d", len(cookies), len(res))
+	}
+	for i := range res {
+		if res[i] != cookies[i] {
+			t.Errorf("[%d]: expected %+v, but got %+v", i, cookies[i], res[i])
+		}
+	}
+
+	if _, err := ParseJarFile([]byte("example.com\tFALSE\t/\n")); err == nil {
+		t.Errorf("expected error, but got nil")
+	}
+	if _, err := ParseJarFile([]byte("example.com\tFALSE\t/\tFALSE\tnever\ta\tb\n")); err == nil {
+		t.Errorf("expected error, but got nil")
+	}
+}
+
+func BenchmarkParseJarFile(b *testing.B) {
+	data := []byte(".example.com\tTRUE\t/\tFALSE\t0\tsid\t1\n")
+	for b.Loop() {
+		ParseJarFile(data)
+	}
+}
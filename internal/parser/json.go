@@ -0,0 +1,505 @@
+// Package parser json.go contains json path scanner.
+// It walks raw json without decoding it.
+package parser
+
+import (
+	"bytes"
+)
+
+// Kinds of path segments.
+const (
+	// segKey for object member, like 'data' or '"a.b"'.
+	segKey = iota
+
+	// segIndex for array element, like '[0]' or '[-1]'.
+	segIndex
+
+	// segWild for any member or element, like '*' or '[*]'.
+	segWild
+)
+
+// pathSeg is a one segment of json path.
+type pathSeg struct {
+	kind int
+	key  []byte
+	idx  int
+}
+
+// JSONPath accepts raw json and path.
+// Path must be like 'data.items[0].id', 'items[*].id' or '["a.b"].c'.
+// It returns raw value and true if value was found.
+// Strings are returned unquoted, other values as raw json.
+// Wildcard paths return json array of all matched values.
+func JSONPath(data, path []byte) ([]byte, bool) {
+	trimBytes(&path, isSpace)
+
+	if len(path) == 0 {
+		val := jsonRoot(data)
+		if len(val) == 0 {
+			return nil, false
+		}
+		return unquote(val), true
+	}
+
+	if !hasWild(path) {
+		val, ok := jsonGet(data, path)
+		if !ok {
+			seg, rest, _ := nextSeg(path)
+			if seg.kind != segKey || len(rest) != 0 {
+				return nil, false
+			}
+			// Single key keeps old behavior: first key at any depth.
+			val, ok = jsonFind(data, seg.key)
+			if !ok {
+				return nil, false
+			}
+		}
+		return unquote(val), true
+	}
+
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufPool.Put(buf)
+
+	buf.WriteByte('[')
+	cnt := 0
+	jsonWalk(data, path, func(val []byte) {
+		if cnt > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(val)
+		cnt++
+	})
+	buf.WriteByte(']')
+
+	if cnt == 0 {
+		return nil, false
+	}
+
+	res := make([]byte, buf.Len())
+	copy(res, buf.Bytes())
+	return res, true
+}
+
+// RangeJSONArray accepts raw json array.
+// Calls yield for each element as raw json.
+// Returns false if data is not array.
+func RangeJSONArray(data []byte, yield func(int, []byte)) bool {
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '[' {
+		return false
+	}
+
+	n := 0
+	rangeElems(data, i, func(s, e int) bool {
+		yield(n, data[s:e])
+		n++
+		return true
+	})
+	return true
+}
+
+// jsonGet returns value by path without wildcards.
+func jsonGet(data, path []byte) ([]byte, bool) {
+	var res []byte
+	found := false
+	jsonWalk(data, path, func(val []byte) {
+		if !found {
+			res = val
+			found = true
+		}
+	})
+	return res, found
+}
+
+// jsonWalk calls yield for each value matched by path.
+func jsonWalk(data, path []byte, yield func([]byte)) {
+	val := jsonRoot(data)
+	if val == nil {
+		return
+	}
+	walk(val, path, yield)
+}
+
+// walk is a recursive part of jsonWalk.
+func walk(val, path []byte, yield func([]byte)) {
+	if len(path) == 0 {
+		yield(val)
+		return
+	}
+
+	seg, rest, ok := nextSeg(path)
+	if !ok {
+		return
+	}
+
+	switch seg.kind {
+	case segKey:
+		if !isObject(val) {
+			return
+		}
+		rangeMembers(val, func(k []byte, s, e int) bool {
+			if bytes.Equal(k, seg.key) {
+				walk(val[s:e], rest, yield)
+				return false
+			}
+			return true
+		})
+	case segIndex:
+		if !isArray(val) {
+			return
+		}
+		idx := seg.idx
+		if idx < 0 {
+			cnt := 0
+			rangeElems(val, 0, func(s, e int) bool {
+				cnt++
+				return true
+			})
+			idx += cnt
+			if idx < 0 {
+				return
+			}
+		}
+		n := 0
+		rangeElems(val, 0, func(s, e int) bool {
+			if n == idx {
+				walk(val[s:e], rest, yield)
+				return false
+			}
+			n++
+			return true
+		})
+	case segWild:
+		if isObject(val) {
+			rangeMembers(val, func(k []byte, s, e int) bool {
+				walk(val[s:e], rest, yield)
+				return true
+			})
+		} else if isArray(val) {
+			rangeElems(val, 0, func(s, e int) bool {
+				walk(val[s:e], rest, yield)
+				return true
+			})
+		}
+	}
+}
+
+// jsonFind finds first member with key at any depth.
+func jsonFind(data, key []byte) ([]byte, bool) {
+	val := jsonRoot(data)
+	if val == nil {
+		return nil, false
+	}
+	return find(val, key)
+}
+
+// find is a recursive part of jsonFind.
+func find(val, key []byte) ([]byte, bool) {
+	var res []byte
+	found := false
+
+	if isObject(val) {
+		rangeMembers(val, func(k []byte, s, e int) bool {
+			if bytes.Equal(k, key) {
+				res, found = val[s:e], true
+				return false
+			}
+			res, found = find(val[s:e], key)
+			return !found
+		})
+	} else if isArray(val) {
+		rangeElems(val, 0, func(s, e int) bool {
+			res, found = find(val[s:e], key)
+			return !found
+		})
+	}
+
+	return res, found
+}
+
+// jsonRoot returns root value of data.
+// Data without braces, like '"key": "val"', is treated as object members.
+func jsonRoot(data []byte) []byte {
+	i := skipSpace(data, 0)
+	if i >= len(data) {
+		return nil
+	}
+
+	end := scanValue(data, i)
+	if end == -1 {
+		return nil
+	}
+
+	if data[i] == '"' {
+		j := skipSpace(data, end)
+		if j < len(data) && data[j] == ':' {
+			return data[i:]
+		}
+	}
+
+	return data[i:end]
+}
+
+// isObject reports whether value is object or bare members.
+func isObject(val []byte) bool {
+	return len(val) > 0 && (val[0] == '{' || val[0] == '"' && !isString(val))
+}
+
+// isArray reports whether value is array.
+func isArray(val []byte) bool {
+	return len(val) > 0 && val[0] == '['
+}
+
+// isString reports whether value is one json string.
+func isString(val []byte) bool {
+	return len(val) > 0 && val[0] == '"' && scanValue(val, 0) == len(val)
+}
+
+// rangeMembers calls yield for each object member.
+// Accepts object or bare members. Stops if yield returns false.
+func rangeMembers(obj []byte, yield func(key []byte, start, end int) bool) {
+	i := 0
+	if obj[0] == '{' {
+		i = 1
+	}
+
+	for {
+		i = skipSpace(obj, i)
+		if i >= len(obj) || obj[i] == '}' {
+			return
+		}
+		if obj[i] == ',' {
+			i++
+			continue
+		}
+		if obj[i] != '"' {
+			return
+		}
+
+		kEnd := scanValue(obj, i)
+		if kEnd == -1 {
+			return
+		}
+		key := obj[i+1 : kEnd-1]
+
+		i = skipSpace(obj, kEnd)
+		if i >= len(obj) || obj[i] != ':' {
+			return
+		}
+		i = skipSpace(obj, i+1)
+
+		vEnd := scanValue(obj, i)
+		if vEnd == -1 {
+			return
+		}
+
+		if !yield(key, i, vEnd) {
+			return
+		}
+		i = vEnd
+	}
+}
+
+// rangeElems calls yield for each array element.
+// Accepts data and index of '['. Stops if yield returns false.
+func rangeElems(arr []byte, start int, yield func(start, end int) bool) {
+	i := start + 1
+
+	for {
+		i = skipSpace(arr, i)
+		if i >= len(arr) || arr[i] == ']' {
+			return
+		}
+		if arr[i] == ',' {
+			i++
+			continue
+		}
+
+		end := scanValue(arr, i)
+		if end == -1 {
+			return
+		}
+
+		if !yield(i, end) {
+			return
+		}
+		i = end
+	}
+}
+
+// scanValue returns end index of value which starts at i.
+// Returns -1 if value is broken.
+func scanValue(data []byte, i int) int {
+	if i >= len(data) {
+		return -1
+	}
+
+	switch data[i] {
+	case '"':
+		for j := i + 1; j < len(data); j++ {
+			switch data[j] {
+			case '\\':
+				j++
+			case '"':
+				return j + 1
+			}
+		}
+		return -1
+	case '{', '[':
+		depth := 0
+		for j := i; j < len(data); j++ {
+			switch data[j] {
+			case '"':
+				end := scanValue(data, j)
+				if end == -1 {
+					return -1
+				}
+				j = end - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return j + 1
+				}
+			}
+		}
+		return -1
+	default:
+		j := i
+		for j < len(data) && !isSpace(data[j]) &&
+			data[j] != ',' && data[j] != '}' && data[j] != ']' {
+			j++
+		}
+		if j == i {
+			return -1
+		}
+		return j
+	}
+}
+
+// nextSeg returns next segment of path and rest of path.
+func nextSeg(path []byte) (pathSeg, []byte, bool) {
+	var seg pathSeg
+
+	if len(path) > 0 && path[0] == '.' {
+		path = path[1:]
+	}
+	if len(path) == 0 {
+		return seg, nil, false
+	}
+
+	switch path[0] {
+	case '[':
+		end := bytes.IndexByte(path, ']')
+		if end == -1 {
+			return seg, nil, false
+		}
+		in := path[1:end]
+		trimBytes(&in, isSpace)
+
+		switch {
+		case len(in) == 1 && in[0] == '*':
+			seg.kind = segWild
+		case len(in) > 1 && (in[0] == '"' || in[0] == '\''):
+			// Key can contain ']', so quote is closed on path after spaces.
+			qStart := 1
+			for isSpace(path[qStart]) {
+				qStart++
+			}
+			qEnd := closeQuote(path, in[0], qStart+1)
+			if qEnd == -1 {
+				return seg, nil, false
+			}
+			end = bytes.IndexByte(path[qEnd:], ']')
+			if end == -1 {
+				return seg, nil, false
+			}
+			end += qEnd
+			seg.kind = segKey
+			seg.key = path[qStart+1 : qEnd]
+		default:
+			neg := len(in) > 0 && in[0] == '-'
+			if neg {
+				in = in[1:]
+			}
+			idx := atoi(in)
+			if idx == Error {
+				return seg, nil, false
+			}
+			if neg {
+				idx = -idx
+			}
+			seg.kind = segIndex
+			seg.idx = idx
+		}
+		return seg, path[end+1:], true
+	case '"', '\'':
+		qEnd := closeQuote(path, path[0], 1)
+		if qEnd == -1 {
+			return seg, nil, false
+		}
+		seg.kind = segKey
+		seg.key = path[1:qEnd]
+		return seg, path[qEnd+1:], true
+	}
+
+	end := 0
+	for end < len(path) && path[end] != '.' && path[end] != '[' {
+		end++
+	}
+
+	if end == 1 && path[0] == '*' {
+		seg.kind = segWild
+	} else {
+		seg.kind = segKey
+		seg.key = path[:end]
+	}
+	return seg, path[end:], true
+}
+
+// closeQuote returns index of closing quote.
+func closeQuote(path []byte, q byte, from int) int {
+	for j := from; j < len(path); j++ {
+		if path[j] == '\\' {
+			j++
+			continue
+		}
+		if path[j] == q {
+			return j
+		}
+	}
+	return -1
+}
+
+// hasWild reports whether path contains wildcard segments.
+func hasWild(path []byte) bool {
+	for {
+		seg, rest, ok := nextSeg(path)
+		if !ok {
+			return false
+		}
+		if seg.kind == segWild {
+			return true
+		}
+		path = rest
+	}
+}
+
+// skipSpace returns index of first non-space byte from i.
+func skipSpace(data []byte, i int) int {
+	for i < len(data) && isSpace(data[i]) {
+		i++
+	}
+	return i
+}
+
+// unquote removes quotes from json string.
+// Escape sequences stay as is.
+func unquote(val []byte) []byte {
+	if isString(val) {
+		return val[1 : len(val)-1]
+	}
+	return val
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"testing"
+)
+
+var jsonRaw = []byte(`{
+	"user": {"id": 42, "role": "admin", "active": true, "tags": ["a", "b"]},
+	"items": [{"id": 1, "name": "one"}, {"id": 2, "name": "two \"q\""}],
+	"a.b": {"c": null},
+	"empty": {}
+}`)
+
+func TestJSONPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+		found    bool
+	}{
+		{"user.id", `42`, true},
+		{"user.role", `admin`, true},
+		{"user.active", `true`, true},
+		{"user.tags", `["a", "b"]`, true},
+		{"user.tags[1]", `b`, true},
+		{"user.tags[-1]", `b`, true},
+		{"items[0]", `{"id": 1, "name": "one"}`, true},
+		{"items[1].name", `two \"q\"`, true},
+		{"items[*].id", `[1,2]`, true},
+		{"items.*.name", `["one","two \"q\""]`, true},
+		{`["a.b"].c`, `null`, true},
+		{`[ "a.b" ].c`, `null`, true},
+		{`[ 'a.b']`, `{"c": null}`, true},
+		{`"a.b".c`, `null`, true},
+		{"empty", `{}`, true},
+		{"name", `one`, true},
+		{"items[5]", ``, false},
+		{"user.id.more", ``, false},
+		{"nop", ``, false},
+		{"items[*].nop", ``, false},
+	}
+
+	for i, tt := range tests {
+		res, ok := JSONPath(jsonRaw, []byte(tt.path))
+		if ok != tt.found {
+			t.Errorf("[%d]: expected found %v, but got %v", i, tt.found, ok)
+		}
+		if string(res) != tt.expected {
+			t.Errorf("[%d]: expected %q, but got %q", i, tt.expected, string(res))
+		}
+	}
+}
+
+func BenchmarkJSONPath(b *testing.B) {
+	path := []byte("items[1].name")
+	for b.Loop() {
+		JSONPath(jsonRaw, path)
+	}
+}
+
+func TestRangeJSONArray(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+		ok       bool
+	}{
+		{`[1, "two", {"a": [3]}]`, []string{`1`, `"two"`, `{"a": [3]}`}, true},
+		{` [] `, nil, true},
+		{`{"a": 1}`, nil, false},
+	}
+
+	for i, tt := range tests {
+		var got []string
+		ok := RangeJSONArray([]byte(tt.input), func(_ int, elem []byte) {
+			got = append(got, string(elem))
+		})
+		if ok != tt.ok {
+			t.Errorf("[%d]: expected %v, but got %v", i, tt.ok, ok)
+		}
+		if len(got) != len(tt.expected) {
+			t.Fatalf("[%d]: expected %q, but got %q", i, tt.expected, got)
+		}
+		for j := range got {
+			if got[j] != tt.expected[j] {
+				t.Errorf("[%d]: expected %q, but got %q", i, tt.expected[j], got[j])
+			}
+		}
+	}
+}
+
+func BenchmarkRangeJSONArray(b *testing.B) {
+	data := []byte(`[1, "two", {"a": [3]}]`)
+	for b.Loop() {
+		RangeJSONArray(data, func(int, []byte) {})
+	}
+}
@@ -23,6 +23,7 @@ var insts = [][]byte{
 	[]byte("RANDOM"),
 	[]byte("VARIABLE"),
 	[]byte("ENVIRONMENT"),
+	[]byte("ROW"),
 }
 
 // markers is a list of markers for config data.
@@ -32,13 +33,18 @@ var markers = [][]byte{
 	[]byte("oneof="),
 	[]byte("key="),
 	[]byte("from="),
+	[]byte("name="),
 }
 
+// nameMarker is a marker for reference to config by name.
+var nameMarker = []byte("name=")
+
 // ParseStream accepts result of scanner and call yield for each config.
 // It also set config dependencies
 // And replces 'replace' config type with target config
 // And replaces config data with data from replace field.
-func ParseStream(sData *[]gscan.Data, yield func(config.Config), log *zap.Logger) error {
+// Defaults are applied before instructions are resolved, defs may be nil.
+func ParseStream(sData *[]gscan.Data, defs *Defaults, yield func(config.Config), log *zap.Logger) error {
 	const op = "parser.parseStream"
 	n := len(*sData)
 	instsPos := make([]config.Dependency, 0, 6)
@@ -47,6 +53,7 @@ func ParseStream(sData *[]gscan.Data, yield func(config.Config), log *zap.Logger
 		zap.String("op", op),
 		zap.Int("count", n))
 
+	names := ConfigNames(sData)
 	targets := make([]int, n)
 	needed := make([]uint64, (n/64)+1)
 	for i, d := range *sData {
@@ -70,11 +77,16 @@ func ParseStream(sData *[]gscan.Data, yield func(config.Config), log *zap.Logger
 		zap.Int("count", n))
 
 	absEnd := 0
+	if defs != nil {
+		absEnd = len(defs.Block)
+	}
 	cache := make([]config.Config, n)
 	for i, d := range *sData {
 		var cfg config.Config
 		var execCfg config.Config
 		instsPos = instsPos[:0]
+		// instData is a data with defaults, d is kept for config end
+		instData := &d
 
 		log.Debug("processing config",
 			zap.String("op", op),
@@ -130,18 +142,23 @@ func ParseStream(sData *[]gscan.Data, yield func(config.Config), log *zap.Logger
 					zap.String("raw", string(d.RawData)))
 				return fmt.Errorf("%s: no config type", op)
 			} else {
-				if err := handleType(&cfg, &tp, &d); err != nil {
+				md, own := defs.apply(d, tp)
+				if err := handleType(&cfg, &tp, &md); err != nil {
 					log.Debug("check cfg failed",
 						zap.String("op", op),
 						zap.String("name", string(d.Name)),
 						zap.String("raw", string(d.RawData)))
 					return fmt.Errorf("%s: cfg №[%d] failed: %w", op, i, err)
 				}
+				for _, f := range own {
+					cfg.SetOwn(f.key, f.val)
+				}
+				instData = &md
 			}
 			execCfg = cfg
 		}
 
-		if err := handleInstructions(&d, insts, func(inst config.Dependency) {
+		if err := handleInstructions(instData, insts, names, func(inst config.Dependency) {
 			instsPos = append(instsPos, inst)
 		}); err != nil {
 			log.Error("check instruction execCfg failed",
@@ -159,9 +176,27 @@ func ParseStream(sData *[]gscan.Data, yield func(config.Config), log *zap.Logger
 					zap.Int("id", inst.TargetID))
 				return fmt.Errorf("%s: invalid instruction target id", op)
 			}
+			if inst.Inner != "" && (inst.TargetID == n ||
+				fastExtract((*sData)[inst.TargetID].RawData, &(*sData)[inst.TargetID].Entries, []byte("Type")) != "import") {
+				log.Error("inner config of not import config",
+					zap.String("op", op),
+					zap.String("name", string(d.Name)),
+					zap.Int("target", inst.TargetID),
+					zap.String("inner", inst.Inner))
+				return fmt.Errorf("%s: cfg №[%d]: config №[%d] is not import, can't point to %q",
+					op, i, inst.TargetID, inst.Inner)
+			}
+			if inst.TargetID == config.DataFromRow &&
+				len(cfg.GetDataFile()) == 0 && len(execCfg.GetDataFile()) == 0 {
+				log.Error("row instruction without data file",
+					zap.String("op", op),
+					zap.String("name", string(d.Name)),
+					zap.String("key", inst.Key))
+				return fmt.Errorf("%s: cfg №[%d]: instruction 'ROW' needs 'DataFile'", op, i)
+			}
 
 			execCfg.SetDependency(config.Dependency{
-				TargetID: inst.TargetID, Key: inst.Key, Start: inst.Start, End: inst.End, InsTp: inst.InsTp,
+				TargetID: inst.TargetID, Key: inst.Key, Start: inst.Start, End: inst.End, InsTp: inst.InsTp, Inner: inst.Inner,
 			})
 			log.Debug("set dependency",
 				zap.String("op", op),
@@ -229,10 +264,24 @@ func handleRepeat(d *gscan.Data) (int, error) {
 	return -1, nil
 }
 
+// ConfigNames maps config names to ids.
+// Name of several configs is mapped to Error.
+func ConfigNames(sData *[]gscan.Data) map[string]int {
+	names := make(map[string]int, len(*sData))
+	for i, d := range *sData {
+		if _, ok := names[string(d.Name)]; ok {
+			names[string(d.Name)] = Error
+			continue
+		}
+		names[string(d.Name)] = i
+	}
+	return names
+}
+
 // handleInstructions extracts dependencies from config.
-// Accepts config and list of instruction names.
+// Accepts config, list of instruction names and config ids by names.
 // Calls yield for each dependency.
-func handleInstructions(d *gscan.Data, insts [][]byte, yield func(inst config.Dependency)) error {
+func handleInstructions(d *gscan.Data, insts [][]byte, names map[string]int, yield func(inst config.Dependency)) error {
 	const op = "parser.handleInstructions"
 
 	start := bytes.IndexByte(d.RawData, '{')
@@ -257,12 +306,14 @@ func handleInstructions(d *gscan.Data, insts [][]byte, yield func(inst config.De
 			depType := 0
 			minIdx := -1
 			markerLen := 0
+			var marker []byte
 			for _, m := range markers {
 				idx := bytes.Index(d.RawData[pIdx:], m)
 				if idx != -1 {
 					if minIdx == -1 || idx < minIdx {
 						minIdx = idx
 						markerLen = len(m)
+						marker = m
 					}
 				}
 			}
@@ -307,19 +358,40 @@ func handleInstructions(d *gscan.Data, insts [][]byte, yield func(inst config.De
 			tID := -1
 			args := d.RawData[valStart:valEnd]
 
+			// 'auth.login' points to config 'login' of file imported by config 'auth'
+			inner := ""
+			if dot := bytes.IndexByte(args, '.'); dot != -1 && (instTp == "RESPONSE" || instTp == "COOKIES") {
+				if dot == len(args)-1 {
+					return fmt.Errorf("%s: empty inner config in %q in instruction type %q", op, args, instTp)
+				}
+				inner = unsafe.String(&args[dot+1], len(args)-dot-1)
+				args = args[:dot]
+			}
+
 			switch {
 			case instTp == "RANDOM":
 				tID = config.RandomData
+			case bytes.Equal(marker, nameMarker) || inner != "" && atoi(args) == Error:
+				id, ok := names[string(args)]
+				if !ok {
+					return fmt.Errorf("%s: no config with name %q in instruction type %q", op, args, instTp)
+				}
+				if id == Error {
+					return fmt.Errorf("%s: config name %q is ambiguous in instruction type %q", op, args, instTp)
+				}
+				tID = id
 			case bytes.Equal(args, []byte("file")):
 				tID = config.DataFromFile
 			case instTp == "VARIABLE":
 				tID = config.DataFromVariable
 			case instTp == "ENVIRONMENT":
 				tID = config.DataFromEnvironment
+			case instTp == "ROW":
+				tID = config.DataFromRow
 			default:
-				tID = atoi(d.RawData[valStart:valEnd])
+				tID = atoi(args)
 				if tID == -1 {
-					return fmt.Errorf("%s: invalid id %q in instruction type %q", op, d.RawData[valStart:valEnd], instTp)
+					return fmt.Errorf("%s: invalid id %q in instruction type %q", op, args, instTp)
 				}
 			}
 
@@ -340,6 +412,7 @@ func handleInstructions(d *gscan.Data, insts [][]byte, yield func(inst config.De
 				End:      localEnd,
 				Key:      instKey,
 				InsTp:    instTp,
+				Inner:    inner,
 			})
 			curOffset = absEnd
 		}
@@ -387,7 +460,8 @@ func handleType(c *config.Config, tp *string, d *gscan.Data) error {
 // It also set config dependencies
 // And replces 'repeat' config type with target config
 // And replaces config data with data from replace field.
-func ParseFindConfig(sData *[]gscan.Data, cfg *config.Config, tID int) error {
+// Defaults are applied like in ParseStream, defs may be nil.
+func ParseFindConfig(sData *[]gscan.Data, defs *Defaults, cfg *config.Config, tID int) error {
 	const op = "parser.ParseFindConfig"
 
 	if tID < 0 || tID >= len(*sData) {
@@ -411,7 +485,7 @@ func ParseFindConfig(sData *[]gscan.Data, cfg *config.Config, tID int) error {
 		parentID := atoi(targetIDBytes)
 
 		var parentCfg config.Config
-		if err := ParseFindConfig(sData, cfg, parentID); err != nil {
+		if err := ParseFindConfig(sData, defs, cfg, parentID); err != nil {
 			return fmt.Errorf("%s: failed to find parent config: %w", op, err)
 		}
 
@@ -421,14 +495,19 @@ func ParseFindConfig(sData *[]gscan.Data, cfg *config.Config, tID int) error {
 			return fmt.Errorf("%s: failed to handle type: %w", op, err)
 		}
 	} else {
-		if err := handleType(cfg, &tp, &d); err != nil {
+		md, own := defs.apply(d, tp)
+		if err := handleType(cfg, &tp, &md); err != nil {
 			return fmt.Errorf("%s: failed to handle type: %w", op, err)
 		}
+		for _, f := range own {
+			(*cfg).SetOwn(f.key, f.val)
+		}
+		d = md
 	}
 
-	if err := handleInstructions(&d, insts, func(inst config.Dependency) {
+	if err := handleInstructions(&d, insts, ConfigNames(sData), func(inst config.Dependency) {
 		(*cfg).SetDependency(config.Dependency{
-			TargetID: inst.TargetID, Key: inst.Key, Start: inst.Start, End: inst.End, InsTp: inst.InsTp,
+			TargetID: inst.TargetID, Key: inst.Key, Start: inst.Start, End: inst.End, InsTp: inst.InsTp, Inner: inst.Inner,
 		})
 	}); err != nil {
 		return fmt.Errorf("%s: failed to handle instructions: %w", op, err)
@@ -36,7 +36,7 @@ func TestParseStream(t *testing.T) {
 	log := zap.NewNop()
 
 	d, _ := gurlf.Scan(raw)
-	if err := ParseStream(&d, func(c config.Config) {
+	if err := ParseStream(&d, nil, func(c config.Config) {
 		if c.GetType() != "http" && c.GetType() != "repeat" {
 			t.Errorf("expected %q, but got %q", "http or repeat", c.GetType())
 		}
@@ -50,7 +50,167 @@ func BenchmarkParseStream(b *testing.B) {
 	log := zap.NewNop()
 
 	for b.Loop() {
-		if err := ParseStream(&d, yield, log); err != nil {
+		if err := ParseStream(&d, nil, yield, log); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+var rowRaw = []byte(`
+	[row]
+	URL:http://localhost:8080/users/{ROW key=id}
+	Body:{"email":"{ROW key=email}"}
+	DataFile:users.csv
+	Type:http
+	[\row]`)
+
+func TestParseStreamRow(t *testing.T) {
+	config.Init()
+	log := zap.NewNop()
+
+	d, _ := gurlf.Scan(rowRaw)
+	rows := 0
+	if err := ParseStream(&d, nil, func(c config.Config) {
+		c.RangeDeps(func(dep config.Dependency) {
+			if dep.TargetID != config.DataFromRow {
+				t.Errorf("expected %d, but got %d", config.DataFromRow, dep.TargetID)
+			}
+			rows++
+		})
+		c.Release()
+	}, log); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows != 2 {
+		t.Errorf("expected %d row dependencies, but got %d", 2, rows)
+	}
+
+	noFile := bytes.Replace(rowRaw, []byte("DataFile:users.csv\n"), nil, 1)
+	d, _ = gurlf.Scan(noFile)
+	if err := ParseStream(&d, nil, yield, log); err == nil {
+		t.Errorf("expected error, but got nil")
+	}
+}
+
+func BenchmarkParseStreamRow(b *testing.B) {
+	d, _ := gurlf.Scan(rowRaw)
+	log := zap.NewNop()
+
+	for b.Loop() {
+		if err := ParseStream(&d, nil, yield, log); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+var nameRaw = []byte(`
+	[login]
+	URL:http://localhost:8080/login
+	Type:http
+	[\login]
+
+	[me]
+	URL:http://localhost:8080/me
+	Headers:Authorization: Bearer {RESPONSE name=login json:token}
+	Type:http
+	[\me]`)
+
+func TestParseStreamName(t *testing.T) {
+	config.Init()
+	log := zap.NewNop()
+
+	d, _ := gurlf.Scan(nameRaw)
+	deps := 0
+	if err := ParseStream(&d, nil, func(c config.Config) {
+		c.RangeDeps(func(dep config.Dependency) {
+			if dep.TargetID != 0 {
+				t.Errorf("expected %d, but got %d", 0, dep.TargetID)
+			}
+			deps++
+		})
+		c.Release()
+	}, log); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deps != 1 {
+		t.Errorf("expected %d dependencies, but got %d", 1, deps)
+	}
+
+	missing := bytes.Replace(nameRaw, []byte("name=login"), []byte("name=logout"), 1)
+	ambiguous := bytes.ReplaceAll(nameRaw, []byte("me]"), []byte("login]"))
+
+	tests := []struct {
+		input []byte
+	}{
+		{missing},
+		{ambiguous},
+	}
+	for i, tt := range tests {
+		d, _ = gurlf.Scan(tt.input)
+		if err := ParseStream(&d, nil, yield, log); err == nil {
+			t.Errorf("[%d]: expected error, but got nil", i)
+		}
+	}
+}
+
+func BenchmarkParseStreamName(b *testing.B) {
+	d, _ := gurlf.Scan(nameRaw)
+	log := zap.NewNop()
+
+	for b.Loop() {
+		if err := ParseStream(&d, nil, yield, log); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+var importRaw = []byte(`
+	[auth]
+	TargetPath:auth.gurlf
+	Type:import
+	[\auth]
+
+	[me]
+	URL:http://localhost:8080/me
+	Headers:Authorization: Bearer {RESPONSE id=auth.login json:token}
+	CookieIn:{COOKIES id=0.2}
+	Type:http
+	[\me]`)
+
+func TestParseStreamImport(t *testing.T) {
+	config.Init()
+	log := zap.NewNop()
+
+	d, _ := gurlf.Scan(importRaw)
+	var inner []string
+	if err := ParseStream(&d, nil, func(c config.Config) {
+		c.RangeDeps(func(dep config.Dependency) {
+			if dep.TargetID != 0 {
+				t.Errorf("expected %d, but got %d", 0, dep.TargetID)
+			}
+			inner = append(inner, dep.Inner)
+		})
+		c.Release()
+	}, log); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(inner) != 2 || inner[0] != "login" || inner[1] != "2" {
+		t.Errorf("expected %q, but got %q", []string{"login", "2"}, inner)
+	}
+
+	notImport := bytes.Replace(importRaw, []byte("Type:import"), []byte("Type:http"), 1)
+	d, _ = gurlf.Scan(notImport)
+	if err := ParseStream(&d, nil, yield, log); err == nil {
+		t.Errorf("expected error, but got nil")
+	}
+}
+
+func BenchmarkParseStreamImport(b *testing.B) {
+	d, _ := gurlf.Scan(importRaw)
+	log := zap.NewNop()
+
+	for b.Loop() {
+		if err := ParseStream(&d, nil, yield, log); err != nil {
 			b.Fatalf("unexpected error: %v", err)
 		}
 	}
@@ -100,7 +260,7 @@ func TestHandleInstructions(t *testing.T) {
 	instsPos := make([]config.Dependency, 0, len(d))
 
 	for _, tt := range tests {
-		if err := handleInstructions(tt.input, insts, func(inst config.Dependency) { instsPos = append(instsPos, inst) }); err != nil {
+		if err := handleInstructions(tt.input, insts, nil, func(inst config.Dependency) { instsPos = append(instsPos, inst) }); err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 	}
@@ -113,7 +273,7 @@ func BenchmarkHandleInstructions(b *testing.B) {
 
 	for b.Loop() {
 		instsPos = instsPos[:0]
-		if err := handleInstructions(&d[1], insts, func(inst config.Dependency) { instsPos = append(instsPos, inst) }); err != nil {
+		if err := handleInstructions(&d[1], insts, nil, func(inst config.Dependency) { instsPos = append(instsPos, inst) }); err != nil {
 			b.Fatalf("unexpected error: %v", err)
 		}
 	}
@@ -221,7 +381,7 @@ func TestParseFindConfig(t *testing.T) {
 	d, _ := gurlf.Scan(raw)
 
 	var cfg config.Config = &config.HTTPConfig{}
-	if err := ParseFindConfig(&d, &cfg, 0); err != nil {
+	if err := ParseFindConfig(&d, nil, &cfg, 0); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	cfg.Release()
@@ -233,7 +393,7 @@ func BenchmarkParseFindConfig(b *testing.B) {
 
 	var cfg config.Config = &config.HTTPConfig{}
 	for b.Loop() {
-		ParseFindConfig(&d, &cfg, 0)
+		ParseFindConfig(&d, nil, &cfg, 0)
 		cfg.Release()
 	}
 }
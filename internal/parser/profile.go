@@ -0,0 +1,48 @@
+// Package parser profile.go parse profiles file and variables from command line.
+// Profile is a named block of values, like '[staging]'.
+// Values are used by '{VARIABLE}' and '{ENVIRONMENT}' instructions.
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	gscan "github.com/Votline/Gurlf/pkg/scanner"
+)
+
+// ParseProfile accepts scanned profiles file and name of profile.
+// Returns values of profile or error if there is no profile with name.
+func ParseProfile(sData []gscan.Data, name string) (map[string][]byte, error) {
+	const op = "parser.ParseProfile"
+
+	names := make([]string, 0, len(sData))
+	for i, d := range sData {
+		if string(d.Name) != name {
+			names = append(names, string(d.Name))
+			continue
+		}
+
+		vals := make(map[string][]byte, len(d.Entries))
+		parseWithMap(sData[i:i+1], func(key string, val []byte, _ string) {
+			if len(key) != 0 {
+				vals[key] = val
+			}
+		})
+		return vals, nil
+	}
+
+	return nil, fmt.Errorf("%s: no profile %q, profiles: %s", op, name, strings.Join(names, ", "))
+}
+
+// ParseVar accepts variable from command line, like 'key=value'.
+// Returns key and value or error if there is no key.
+func ParseVar(kv string) (string, []byte, error) {
+	const op = "parser.ParseVar"
+
+	key, val, ok := strings.Cut(kv, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", nil, fmt.Errorf("%s: variable must be like 'key=value', but got %q", op, kv)
+	}
+	return key, []byte(val), nil
+}
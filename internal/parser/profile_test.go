@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/Votline/Gurlf"
+)
+
+var profRaw = []byte(`[local]
+host: localhost:8080
+token: dev
+[\local]
+
+[staging]
+host: staging.example.com
+token: abc
+[\staging]`)
+
+func TestParseProfile(t *testing.T) {
+	d, _ := gurlf.Scan(profRaw)
+
+	tests := []struct {
+		name     string
+		expected map[string]string
+		err      bool
+	}{
+		{"local", map[string]string{"host": "localhost:8080", "token": "dev"}, false},
+		{"staging", map[string]string{"host": "staging.example.com", "token": "abc"}, false},
+		{"prod", nil, true},
+	}
+
+	for i, tt := range tests {
+		res, err := ParseProfile(d, tt.name)
+		if tt.err {
+			if err == nil {
+				t.Errorf("[%d]: expected error, but got nil", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d]: unexpected error: %v", i, err)
+			continue
+		}
+
+		if len(res) != len(tt.expected) {
+			t.Errorf("[%d]: expected %d values, but got %d", i, len(tt.expected), len(res))
+		}
+		for k, v := range tt.expected {
+			if string(res[k]) != v {
+				t.Errorf("[%d]: %q: expected %q, but got %q", i, k, v, res[k])
+			}
+		}
+	}
+}
+
+func BenchmarkParseProfile(b *testing.B) {
+	d, _ := gurlf.Scan(profRaw)
+	for b.Loop() {
+		ParseProfile(d, "staging")
+	}
+}
+
+func TestParseVar(t *testing.T) {
+	tests := []struct {
+		input string
+		key   string
+		val   string
+		err   bool
+	}{
+		{"host=localhost", "host", "localhost", false},
+		{" host =a=b", "host", "a=b", false},
+		{"empty=", "empty", "", false},
+		{"host", "", "", true},
+		{"=value", "", "", true},
+	}
+
+	for i, tt := range tests {
+		key, val, err := ParseVar(tt.input)
+		if tt.err {
+			if err == nil {
+				t.Errorf("[%d]: expected error, but got nil", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d]: unexpected error: %v", i, err)
+			continue
+		}
+
+		if key != tt.key || string(val) != tt.val {
+			t.Errorf("[%d]: expected %q=%q, but got %q=%q", i, tt.key, tt.val, key, val)
+		}
+	}
+}
+
+func BenchmarkParseVar(b *testing.B) {
+	for b.Loop() {
+		ParseVar("host=localhost")
+	}
+}
@@ -0,0 +1,299 @@
+// Package parser retry.go parse retry policy.
+// Policy is a 'Retry' field, like '3;backoff=exp;base=200ms;on=5xx,timeout'.
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// Kinds of backoff.
+const (
+	// BackoffConst for same delay before each attempt.
+	BackoffConst = iota
+
+	// BackoffLinear for delay growing by base.
+	BackoffLinear
+
+	// BackoffExp for delay doubling after each attempt.
+	BackoffExp
+)
+
+// Kinds of retry conditions.
+const (
+	// RetryOnStatus for exact response code, like '503'.
+	RetryOnStatus = iota
+
+	// RetryOnClass for HTTP code class, like '5xx'.
+	RetryOnClass
+
+	// RetryOnGRPC for gRPC code name, like 'UNAVAILABLE'.
+	RetryOnGRPC
+
+	// RetryOnTimeout for timeout of request or 'DEADLINE_EXCEEDED'.
+	RetryOnTimeout
+
+	// RetryOnError for any transport error.
+	RetryOnError
+
+	// RetryOnExpect for failed 'Expect' or 'Assert'.
+	RetryOnExpect
+)
+
+// Default values of retry policy.
+const (
+	retryDefBase    = 100 * time.Millisecond
+	retryDefBackoff = BackoffExp
+)
+
+// retryDefOn is a default conditions of retry policy.
+var retryDefOn = []RetryCond{
+	{Kind: RetryOnError},
+	{Kind: RetryOnClass, Code: 5},
+	{Kind: RetryOnGRPC, Code: 14},
+}
+
+// grpcCodes maps gRPC code names to codes.
+var grpcCodes = map[string]int{
+	"OK":                  0,
+	"CANCELLED":           1,
+	"UNKNOWN":             2,
+	"INVALID_ARGUMENT":    3,
+	"DEADLINE_EXCEEDED":   4,
+	"NOT_FOUND":           5,
+	"ALREADY_EXISTS":      6,
+	"PERMISSION_DENIED":   7,
+	"RESOURCE_EXHAUSTED":  8,
+	"FAILED_PRECONDITION": 9,
+	"ABORTED":             10,
+	"OUT_OF_RANGE":        11,
+	"UNIMPLEMENTED":       12,
+	"INTERNAL":            13,
+	"UNAVAILABLE":         14,
+	"DATA_LOSS":           15,
+	"UNAUTHENTICATED":     16,
+}
+
+// RetryCond is a one condition of retry policy.
+type RetryCond struct {
+	// Kind is a kind of condition. Like RetryOnClass.
+	Kind int
+
+	// Code is a response code, code class or gRPC code.
+	Code int
+}
+
+// RetryPolicy is a parsed 'Retry' field.
+type RetryPolicy struct {
+	// Retries is a max count of re-sends.
+	Retries int
+
+	// Backoff is a kind of delay growth. Like BackoffExp.
+	Backoff int
+
+	// Base is a delay before first re-send.
+	Base time.Duration
+
+	// Max is a max delay. Zero for unlimited.
+	Max time.Duration
+
+	// On is a list of conditions for re-send.
+	On []RetryCond
+}
+
+// FailRetryPolicy returns policy for 'fail=retry(n)' action of 'Expect'.
+// Config is re-sent up to n times while 'Expect' or 'Assert' fails.
+func FailRetryPolicy(n int) RetryPolicy {
+	return RetryPolicy{
+		Retries: n,
+		Backoff: retryDefBackoff,
+		Base:    retryDefBase,
+		On:      []RetryCond{{Kind: RetryOnExpect}},
+	}
+}
+
+// ParseRetry accepts retry field from config.
+// Field must be like '3;backoff=exp;base=200ms;max=5s;on=5xx,timeout,UNAVAILABLE'.
+// Only count of retries is required.
+// Backoff is one of 'const', 'linear' or 'exp'.
+// Conditions are codes, code classes, gRPC code names,
+// 'timeout', 'error' and 'expect'.
+func ParseRetry(retry []byte) (RetryPolicy, error) {
+	const op = "parser.ParseRetry"
+
+	p := RetryPolicy{Backoff: retryDefBackoff, Base: retryDefBase}
+
+	var err error
+	first := true
+	RangeByByte(retry, ';', func(start, end int) {
+		if err != nil {
+			return
+		}
+
+		part := retry[start:end]
+		trimBytes(&part, isSpace)
+
+		if first {
+			first = false
+			if p.Retries = atoi(part); p.Retries == Error {
+				err = fmt.Errorf("%s: invalid count of retries %q", op, part)
+			}
+			return
+		}
+		if len(part) == 0 {
+			return
+		}
+
+		key, val, found := bytes.Cut(part, []byte("="))
+		trimBytes(&key, isSpace)
+		trimBytes(&val, isSpace)
+		if !found || len(val) == 0 {
+			err = fmt.Errorf("%s: invalid option %q", op, part)
+			return
+		}
+
+		switch string(key) {
+		case "backoff":
+			switch string(val) {
+			case "const", "fixed":
+				p.Backoff = BackoffConst
+			case "linear":
+				p.Backoff = BackoffLinear
+			case "exp":
+				p.Backoff = BackoffExp
+			default:
+				err = fmt.Errorf("%s: unknown backoff %q", op, val)
+			}
+		case "base":
+			if p.Base = ParseWait(val); p.Base == Error {
+				err = fmt.Errorf("%s: invalid base %q", op, val)
+			}
+		case "max":
+			if p.Max = ParseWait(val); p.Max == Error {
+				err = fmt.Errorf("%s: invalid max %q", op, val)
+			}
+		case "on":
+			p.On, err = parseRetryOn(val)
+		default:
+			err = fmt.Errorf("%s: unknown option %q", op, key)
+		}
+	})
+	if err != nil {
+		return p, err
+	}
+	if first {
+		return p, fmt.Errorf("%s: no count of retries", op)
+	}
+
+	if p.On == nil {
+		p.On = retryDefOn
+	}
+	return p, nil
+}
+
+// parseRetryOn parses comma separated conditions.
+func parseRetryOn(on []byte) ([]RetryCond, error) {
+	const op = "parser.parseRetryOn"
+
+	conds := make([]RetryCond, 0, 4)
+	ch := chunker{data: on, done: false}
+	for {
+		chunk, ok := ch.next()
+		if !ok {
+			break
+		}
+		trimBytes(&chunk, isSpace)
+		if len(chunk) == 0 {
+			continue
+		}
+
+		switch {
+		case EqualFold(chunk, "timeout"):
+			conds = append(conds, RetryCond{Kind: RetryOnTimeout})
+		case EqualFold(chunk, "error"):
+			conds = append(conds, RetryCond{Kind: RetryOnError})
+		case EqualFold(chunk, "expect"):
+			conds = append(conds, RetryCond{Kind: RetryOnExpect})
+		case len(chunk) == 3 && chunk[0] >= '1' && chunk[0] <= '5' &&
+			(chunk[1] == 'x' || chunk[1] == 'X') && (chunk[2] == 'x' || chunk[2] == 'X'):
+			conds = append(conds, RetryCond{Kind: RetryOnClass, Code: int(chunk[0] - '0')})
+		default:
+			if code := atoi(chunk); code != Error {
+				conds = append(conds, RetryCond{Kind: RetryOnStatus, Code: code})
+				continue
+			}
+			code, ok := grpcCodes[string(bytes.ToUpper(chunk))]
+			if !ok {
+				return nil, fmt.Errorf("%s: unknown condition %q", op, chunk)
+			}
+			conds = append(conds, RetryCond{Kind: RetryOnGRPC, Code: code})
+		}
+	}
+
+	return conds, nil
+}
+
+// RetryDelay accepts policy and number of re-send from 1.
+// It returns delay before re-send.
+func RetryDelay(p RetryPolicy, n int) time.Duration {
+	d := p.Base
+	switch p.Backoff {
+	case BackoffLinear:
+		d = p.Base * time.Duration(n)
+	case BackoffExp:
+		for i := 1; i < n; i++ {
+			d *= 2
+			if p.Max > 0 && d >= p.Max {
+				break
+			}
+		}
+	}
+
+	if p.Max > 0 && d > p.Max {
+		d = p.Max
+	}
+	return d
+}
+
+// RetryMatch accepts policy and outcome of attempt.
+// Code and isGRPC are taken from response.
+// Returns true if config must be re-sent.
+func RetryMatch(p RetryPolicy, code int, isGRPC, isErr, isTimeout, expectFail bool) bool {
+	for _, c := range p.On {
+		switch c.Kind {
+		case RetryOnError:
+			if isErr {
+				return true
+			}
+		case RetryOnTimeout:
+			if isTimeout || isGRPC && code == grpcCodes["DEADLINE_EXCEEDED"] {
+				return true
+			}
+		case RetryOnExpect:
+			if expectFail {
+				return true
+			}
+		}
+
+		if isErr {
+			continue
+		}
+
+		switch c.Kind {
+		case RetryOnStatus:
+			if code == c.Code {
+				return true
+			}
+		case RetryOnClass:
+			if !isGRPC && code/100 == c.Code {
+				return true
+			}
+		case RetryOnGRPC:
+			if isGRPC && code == c.Code {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRetry(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected RetryPolicy
+		err      bool
+	}{
+		{
+			"3",
+			RetryPolicy{Retries: 3, Backoff: BackoffExp, Base: 100 * time.Millisecond, On: retryDefOn},
+			false,
+		},
+		{
+			"3;backoff=exp;base=200ms;on=5xx,timeout,UNAVAILABLE",
+			RetryPolicy{Retries: 3, Backoff: BackoffExp, Base: 200 * time.Millisecond, On: []RetryCond{
+				{Kind: RetryOnClass, Code: 5},
+				{Kind: RetryOnTimeout},
+				{Kind: RetryOnGRPC, Code: 14},
+			}},
+			false,
+		},
+		{
+			"2; backoff = linear; base=1s; max=3s; on=429, expect",
+			RetryPolicy{Retries: 2, Backoff: BackoffLinear, Base: time.Second, Max: 3 * time.Second, On: []RetryCond{
+				{Kind: RetryOnStatus, Code: 429},
+				{Kind: RetryOnExpect},
+			}},
+			false,
+		},
+		{"", RetryPolicy{}, true},
+		{"x", RetryPolicy{}, true},
+		{"3;backoff=slow", RetryPolicy{}, true},
+		{"3;base=soon", RetryPolicy{}, true},
+		{"3;on=NOPE", RetryPolicy{}, true},
+		{"3;tries", RetryPolicy{}, true},
+	}
+
+	for i, tt := range tests {
+		res, err := ParseRetry([]byte(tt.input))
+		if tt.err {
+			if err == nil {
+				t.Errorf("[%d]: expected error, but got nil", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d]: unexpected error: %v", i, err)
+			continue
+		}
+
+		if res.Retries != tt.expected.Retries || res.Backoff != tt.expected.Backoff ||
+			res.Base != tt.expected.Base || res.Max != tt.expected.Max {
+			t.Errorf("[%d]: expected %+v, but got %+v", i, tt.expected, res)
+		}
+		if len(res.On) != len(tt.expected.On) {
+			t.Errorf("[%d]: expected %v, but got %v", i, tt.expected.On, res.On)
+			continue
+		}
+		for j := range res.On {
+			if res.On[j] != tt.expected.On[j] {
+				t.Errorf("[%d]: expected %v, but got %v", i, tt.expected.On[j], res.On[j])
+			}
+		}
+	}
+}
+
+func BenchmarkParseRetry(b *testing.B) {
+	retry := []byte("3;backoff=exp;base=200ms;on=5xx,timeout,UNAVAILABLE")
+	for b.Loop() {
+		ParseRetry(retry)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		policy   RetryPolicy
+		n        int
+		expected time.Duration
+	}{
+		{RetryPolicy{Backoff: BackoffConst, Base: time.Second}, 3, time.Second},
+		{RetryPolicy{Backoff: BackoffLinear, Base: time.Second}, 3, 3 * time.Second},
+		{RetryPolicy{Backoff: BackoffExp, Base: time.Second}, 1, time.Second},
+		{RetryPolicy{Backoff: BackoffExp, Base: time.Second}, 4, 8 * time.Second},
+		{RetryPolicy{Backoff: BackoffExp, Base: time.Second, Max: 5 * time.Second}, 4, 5 * time.Second},
+		{RetryPolicy{Backoff: BackoffExp, Base: time.Second, Max: 5 * time.Second}, 100, 5 * time.Second},
+	}
+
+	for i, tt := range tests {
+		res := RetryDelay(tt.policy, tt.n)
+		if res != tt.expected {
+			t.Errorf("[%d]: expected %s, but got %s", i, tt.expected, res)
+		}
+	}
+}
+
+func BenchmarkRetryDelay(b *testing.B) {
+	p := RetryPolicy{Backoff: BackoffExp, Base: time.Second, Max: time.Minute}
+	for b.Loop() {
+		RetryDelay(p, 5)
+	}
+}
+
+func TestRetryMatch(t *testing.T) {
+	p, _ := ParseRetry([]byte("3;on=5xx,429,timeout,UNAVAILABLE"))
+
+	tests := []struct {
+		code       int
+		isGRPC     bool
+		isErr      bool
+		isTimeout  bool
+		expectFail bool
+		expected   bool
+	}{
+		{503, false, false, false, false, true},
+		{429, false, false, false, false, true},
+		{404, false, false, false, false, false},
+		{14, true, false, false, false, true},
+		{4, true, false, false, false, true},
+		{5, true, false, false, false, false},
+		{0, false, true, true, false, true},
+		{0, false, true, false, false, false},
+		{200, false, false, false, true, false},
+	}
+
+	for i, tt := range tests {
+		res := RetryMatch(p, tt.code, tt.isGRPC, tt.isErr, tt.isTimeout, tt.expectFail)
+		if res != tt.expected {
+			t.Errorf("[%d]: expected %v, but got %v", i, tt.expected, res)
+		}
+	}
+}
+
+func BenchmarkRetryMatch(b *testing.B) {
+	p, _ := ParseRetry([]byte("3;on=5xx,429,timeout,UNAVAILABLE"))
+	for b.Loop() {
+		RetryMatch(p, 503, false, false, false, false)
+	}
+}
@@ -5,9 +5,9 @@ package transport
 import (
 	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"fmt"
-	"os"
+	"net"
+	"net/http"
 	"path/filepath"
 	"strings"
 	"time"
@@ -26,6 +26,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	reflectpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 	"google.golang.org/grpc/status"
 )
@@ -49,9 +50,83 @@ func (t *Transport) DoGRPC(c *config.GRPCConfig, resObj *Result) error {
 
 	resObj.Raw = res.Raw
 	resObj.Info = res.Info
+	resObj.Proto = res.Proto
+	resObj.Header = res.Header
+	resObj.Trailer = res.Trailer
+	resObj.Size = res.Size
+	resObj.Duration = res.Duration
+	resObj.TLS = res.TLS
 	return nil
 }
 
+// invoke sends gRPC request and reads response with metadata.
+func invoke(ctx context.Context, conn *grpc.ClientConn, mthd *desc.MethodDescriptor, msg *dynamic.Message) (Result, error) {
+	const op = "transport.invoke"
+
+	var hdr, trl metadata.MD
+	var p peer.Peer
+	stub := grpcdynamic.NewStub(conn)
+
+	start := time.Now()
+	rpcRes, err := stub.InvokeRpc(ctx, mthd, msg, grpc.Header(&hdr), grpc.Trailer(&trl), grpc.Peer(&p))
+	res := Result{
+		Proto:    "grpc",
+		Header:   mdToHeader(hdr),
+		Trailer:  mdToHeader(trl),
+		Duration: time.Since(start),
+	}
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		res.TLS = newTLSInfo(&info.State)
+	}
+
+	if err != nil {
+		st, ok := status.FromError(err)
+		if !ok {
+			res.Raw = []byte(err.Error())
+			res.Size = len(res.Raw)
+			return res, nil
+		}
+		res.Raw = []byte(st.Message())
+		res.Size = len(res.Raw)
+		res.Info = Status{
+			Code:       int(st.Code()),
+			Message:    st.Message(),
+			ConfigType: "grpc",
+		}
+		return res, nil
+	}
+
+	dMsg, ok := rpcRes.(*dynamic.Message)
+	if !ok {
+		return Result{}, fmt.Errorf("%s: type assert response: invalid response type", op)
+	}
+
+	res.Raw = parseMsg(dMsg)
+	res.Size = len(res.Raw)
+	res.Info = Status{
+		Code:       0,
+		Message:    "0 OK",
+		ConfigType: "grpc",
+	}
+	return res, nil
+}
+
+// mdToHeader converts metadata to headers.
+// Keys are canonicalized, so Header.Get works for both transports.
+func mdToHeader(md metadata.MD) http.Header {
+	if len(md) == 0 {
+		return nil
+	}
+
+	h := make(http.Header, len(md))
+	for k, vals := range md {
+		for _, v := range vals {
+			h.Add(k, v)
+		}
+	}
+	return h
+}
+
 // doReflect sends gRPC request via reflection.
 func (t *Transport) doReflect(c *config.GRPCConfig) (Result, error) {
 	const op = "transport.doReflect"
@@ -60,7 +135,15 @@ func (t *Transport) doReflect(c *config.GRPCConfig) (Result, error) {
 	endpoint := unsafe.String(unsafe.SliceData(c.Endpoint), len(c.Endpoint))
 	dialOpts := unsafe.String(unsafe.SliceData(c.DialOpts), len(c.DialOpts))
 
-	conn, err := t.getConn(target, dialOpts, c.GetCerts())
+	tk, err := newTLSKey(c.GetCerts(), &c.TLSConfig)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %w", op, err)
+	}
+	pk, err := newProxyKey(t.ses.proxy, &c.ProxyConfig)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %w", op, err)
+	}
+	conn, err := t.getConn(target, dialOpts, c.GetCerts(), tk, pk)
 	if err != nil {
 		return Result{}, fmt.Errorf("%s: %w", op, err)
 	}
@@ -72,10 +155,11 @@ func (t *Transport) doReflect(c *config.GRPCConfig) (Result, error) {
 			zap.String("target", target))
 	}
 
-	ctx, err := getContext(c.Metadata, c.Timeout)
+	ctx, cancel, err := getContext(c.Metadata, c.Timeout)
 	if err != nil {
 		return Result{}, fmt.Errorf("%s: %w", op, err)
 	}
+	defer cancel()
 
 	rc := refl.NewClient(ctx, reflectpb.NewServerReflectionClient(conn))
 	svcName, mtName := parseEndpoint(endpoint)
@@ -96,30 +180,11 @@ func (t *Transport) doReflect(c *config.GRPCConfig) (Result, error) {
 		}
 	}
 
-	stub := grpcdynamic.NewStub(conn)
-	rpcRes, err := stub.InvokeRpc(ctx, mthd, msg)
+	res, err := invoke(ctx, conn, mthd, msg)
 	if err != nil {
-		st, ok := status.FromError(err)
-		if !ok {
-			return Result{Raw: []byte(err.Error())}, nil
-		}
-		return Result{Raw: []byte(st.Message()), Info: Status{
-			Code:       int(st.Code()),
-			Message:    st.Message(),
-			ConfigType: "grpc",
-		}}, nil
-	}
-
-	dMsg, ok := rpcRes.(*dynamic.Message)
-	if !ok {
-		return Result{}, fmt.Errorf("%s: type assert response: invalid response type", op)
+		return Result{}, fmt.Errorf("%s: %w", op, err)
 	}
-
-	return Result{Raw: parseMsg(dMsg), Info: Status{
-		Code:       0,
-		Message:    "0 OK",
-		ConfigType: "grpc",
-	}}, nil
+	return res, nil
 }
 
 // doProto sends gRPC request via protofiles.
@@ -132,7 +197,15 @@ func (t *Transport) doProto(c *config.GRPCConfig) (Result, error) {
 	importPaths := unsafe.String(unsafe.SliceData(c.ImportPaths), len(c.ImportPaths))
 	dialOpts := unsafe.String(unsafe.SliceData(c.DialOpts), len(c.DialOpts))
 
-	conn, err := t.getConn(target, dialOpts, c.GetCerts())
+	tk, err := newTLSKey(c.GetCerts(), &c.TLSConfig)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %w", op, err)
+	}
+	pk, err := newProxyKey(t.ses.proxy, &c.ProxyConfig)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %w", op, err)
+	}
+	conn, err := t.getConn(target, dialOpts, c.GetCerts(), tk, pk)
 	if err != nil {
 		return Result{}, fmt.Errorf("%s: %w", op, err)
 	}
@@ -144,10 +217,11 @@ func (t *Transport) doProto(c *config.GRPCConfig) (Result, error) {
 			zap.String("target", target))
 	}
 
-	ctx, err := getContext(c.Metadata, c.Timeout)
+	ctx, cancel, err := getContext(c.Metadata, c.Timeout)
 	if err != nil {
 		return Result{}, fmt.Errorf("%s: %w", op, err)
 	}
+	defer cancel()
 
 	protoDir := filepath.Dir(protoPath)
 	protoFile := filepath.Base(protoPath)
@@ -182,44 +256,53 @@ func (t *Transport) doProto(c *config.GRPCConfig) (Result, error) {
 		}
 	}
 
-	stub := grpcdynamic.NewStub(conn)
-	rpcRes, err := stub.InvokeRpc(ctx, mthd, msg)
+	res, err := invoke(ctx, conn, mthd, msg)
 	if err != nil {
-		st, ok := status.FromError(err)
-		if !ok {
-			return Result{Raw: []byte(err.Error())}, nil
-		}
-		return Result{Raw: []byte(st.Message()), Info: Status{
-			Code:       int(st.Code()),
-			Message:    st.Message(),
-			ConfigType: "grpc",
-		}}, nil
-	}
-
-	dMsg, ok := rpcRes.(*dynamic.Message)
-	if !ok {
-		return Result{}, fmt.Errorf("%s: type assert response: invalid response type", op)
+		return Result{}, fmt.Errorf("%s: %w", op, err)
 	}
-
-	return Result{Raw: parseMsg(dMsg), Info: Status{
-		Code:       0,
-		Message:    "0 OK",
-		ConfigType: "grpc",
-	}}, nil
+	return res, nil
 }
 
-// getConn parses target, insecureSkipVerify and dial options.
+// getConn parses target, TLS settings, proxy and dial options.
+// Target can be 'host:port', 'dns:///host:port' or unix socket 'unix:///run/app.sock'.
+// Proxy of 'https' requests is used, like grpc-go does for env vars.
 // Return client connection and error.
-func (t *Transport) getConn(target string, dialOpts string, certsPath []byte) (*grpc.ClientConn, error) {
+func (t *Transport) getConn(target string, dialOpts string, certsPath []byte, tk tlsKey, pk proxyKey) (*grpc.ClientConn, error) {
 	const op = "transport.getConn"
 
-	opts := make([]grpc.DialOption, 0, strings.Count(dialOpts, ";"))
-	if err := t.getDialOpts(dialOpts, certsPath, func(opt grpc.DialOption) {
+	opts := make([]grpc.DialOption, 0, strings.Count(dialOpts, ";")+2)
+	if err := t.getDialOpts(dialOpts, certsPath, tk, func(opt grpc.DialOption) {
 		opts = append(opts, opt)
 	}); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	// Proxy env vars are handled by session, not by grpc-go.
+	opts = append(opts, grpc.WithNoProxy())
+
+	addr := target
+	switch {
+	case strings.HasPrefix(target, "unix:"), strings.HasPrefix(target, "unix-abstract:"):
+		// Unix sockets are local and resolved by grpc-go, so proxy isn't used.
+		addr = ""
+	case strings.HasPrefix(target, "dns:///"):
+		addr = target[len("dns:///"):]
+	case strings.Contains(target, ":///"):
+		// Other resolvers are not proxied.
+		addr = ""
+	}
+	pu, err := pk.proxyURL(addr, true)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if addr != "" && pu != nil {
+		// Host is resolved by proxy, not by DNS resolver of grpc-go.
+		target = "passthrough:///" + addr
+		opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return pk.dial(ctx, addr, true)
+		}))
+	}
+
 	conn, err := grpc.NewClient(target, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("%s: dial: %w", op, err)
@@ -229,16 +312,17 @@ func (t *Transport) getConn(target string, dialOpts string, certsPath []byte) (*
 }
 
 // getDialOpts parse dial options and yields them.
-func (t *Transport) getDialOpts(rawOpts string, certsPath []byte, yield func(grpc.DialOption)) error {
+// Without dial options connection is plain if no TLS field is set.
+func (t *Transport) getDialOpts(rawOpts string, certsPath []byte, tk tlsKey, yield func(grpc.DialOption)) error {
 	const op = "transport.getDialOpts"
 
-	if rawOpts == "" && certsPath == nil {
+	if rawOpts == "" && certsPath == nil && tk == (tlsKey{}) {
 		yield(grpc.WithInsecure())
 		return nil
 	}
 
-	if rawOpts == "" && certsPath != nil {
-		tlsCfg, err := getTLSConfig(certsPath)
+	if rawOpts == "" {
+		tlsCfg, err := tk.config()
 		if err != nil {
 			return fmt.Errorf("%s: %w", op, err)
 		}
@@ -257,14 +341,22 @@ func (t *Transport) getDialOpts(rawOpts string, certsPath []byte, yield func(grp
 		case "insecure":
 			yield(grpc.WithInsecure())
 		case "tls":
-			tlsCfg, err = getTLSConfig(certsPath)
+			tlsCfg, err = tk.config()
 			if err != nil {
 				err = fmt.Errorf("%s: %w", op, err)
 				return
 			}
 			yield(grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
 		case "tls_insecure":
-			yield(grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})))
+			// Client certificate is still sent, server certificate isn't verified.
+			insecure := tk
+			insecure.certs = ""
+			tlsCfg, err = insecure.config()
+			if err != nil {
+				err = fmt.Errorf("%s: %w", op, err)
+				return
+			}
+			yield(grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
 		case "block":
 			yield(grpc.WithBlock())
 		case "timeout":
@@ -278,46 +370,26 @@ func (t *Transport) getDialOpts(rawOpts string, certsPath []byte, yield func(grp
 	return err
 }
 
-func getTLSConfig(certsPath []byte) (*tls.Config, error) {
-	const op = "transport.getTLSConfig"
-
-	if certsPath == nil || parser.EqualFold(certsPath, "ignore") {
-		return &tls.Config{InsecureSkipVerify: true}, nil
-	}
-
-	path := unsafe.String(unsafe.SliceData(certsPath), len(certsPath))
-	caCert, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("%s: read certificate: %w", op, err)
-	}
-
-	caCertPool := x509.NewCertPool()
-	if ok := caCertPool.AppendCertsFromPEM(caCert); !ok {
-		return nil, fmt.Errorf("%s: append certificate: %w", op, err)
-	}
-	return &tls.Config{
-		InsecureSkipVerify: false,
-		RootCAs:            caCertPool,
-	}, nil
-}
-
 // getContext parses metadata and timeout.
-// Return context and error.
-func getContext(cfgMd []byte, cfgTm []byte) (context.Context, error) {
+// Return context, its cancel function and error.
+func getContext(cfgMd []byte, cfgTm []byte) (context.Context, context.CancelFunc, error) {
 	const op = "transport.getContext"
 
-	var timeout time.Duration = 10
+	timeout := 10 * time.Second
 	if cfgTm != nil {
-		timeout = parser.ParseWait(cfgTm)
+		if tm := parser.ParseWait(cfgTm); tm > 0 {
+			timeout = tm
+		}
 	}
 
-	ctx, _ := context.WithTimeout(context.Background(), timeout*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 
 	if len(cfgMd) > 0 {
 		md := make(map[string]string)
 		sData, err := gurlf.Scan(cfgMd)
 		if err != nil {
-			return nil, fmt.Errorf("%s: scan metadata: %w", op, err)
+			cancel()
+			return nil, nil, fmt.Errorf("%s: scan metadata: %w", op, err)
 		}
 
 		for _, d := range sData {
@@ -339,7 +411,7 @@ func getContext(cfgMd []byte, cfgTm []byte) (context.Context, error) {
 		ctx = metadata.NewOutgoingContext(ctx, metadata.New(md))
 	}
 
-	return ctx, nil
+	return ctx, cancel, nil
 }
 
 // getDependencyPaths parses protoPath and return dependency paths.
@@ -5,13 +5,9 @@ package transport
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
-	"strings"
 	"time"
 	"unsafe"
 
@@ -34,7 +30,7 @@ func (t *Transport) DoHTTP(c *config.HTTPConfig, resObj *Result, dp bool) error
 	}
 
 	timeout := parser.ParseWait(c.Timeout)
-	if timeout == 0 {
+	if timeout <= 0 {
 		timeout = 2 * time.Second
 		t.log.Warn("timeout is empty. Using default timeout of 2 seconds",
 			zap.String("op", op),
@@ -42,7 +38,7 @@ func (t *Transport) DoHTTP(c *config.HTTPConfig, resObj *Result, dp bool) error
 			zap.Int("id", c.GetID()))
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	req, err := t.prepareRequest(c, ctx)
@@ -50,6 +46,7 @@ func (t *Transport) DoHTTP(c *config.HTTPConfig, resObj *Result, dp bool) error
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	start := time.Now()
 	res, err := t.clientDo(req, c, timeout)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
@@ -60,7 +57,13 @@ func (t *Transport) DoHTTP(c *config.HTTPConfig, resObj *Result, dp bool) error
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
+	resObj.Duration = time.Since(start)
 	resObj.Cookie = parser.ParseCookies(req.URL, res.Cookies())
+	resObj.Proto = res.Proto
+	resObj.Header = res.Header
+	resObj.Trailer = res.Trailer
+	resObj.Size = len(resObj.Raw)
+	resObj.TLS = newTLSInfo(res.TLS)
 
 	resObj.Info = Status{
 		Code:       res.StatusCode,
@@ -82,6 +85,9 @@ func (t *Transport) prepareRequest(c *config.HTTPConfig, ctx context.Context) (*
 
 	mtd := unsafe.String(unsafe.SliceData(c.Method), len(c.Method))
 	url := unsafe.String(unsafe.SliceData(c.URL), len(c.URL))
+	if _, unixURL, ok := parser.ParseUnixURL(c.URL); ok {
+		url = unixURL
+	}
 
 	var bRdr io.Reader
 	if c.Body != nil {
@@ -111,54 +117,27 @@ func (t *Transport) prepareRequest(c *config.HTTPConfig, ctx context.Context) (*
 // clientDo sends request and return response and error.
 func (t *Transport) clientDo(req *http.Request, c *config.HTTPConfig, timeout time.Duration) (*http.Response, error) {
 	const op = "transport.clientDo"
-	if c.GetCerts() == nil || parser.EqualFold(c.GetCerts(), "ignore") {
-		t.cl.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
+
+	tr, err := t.httpTransport(c)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if tr.TLSClientConfig.InsecureSkipVerify {
 		t.log.Warn("InsecureSkipVerify is true",
 			zap.String("op", op),
 			zap.String("url", req.URL.String()))
-	} else {
-		path := unsafe.String(unsafe.SliceData(c.GetCerts()), len(c.GetCerts()))
-		caCert, err := os.ReadFile(path)
-		if err != nil {
-			return nil, fmt.Errorf("%s: read certificate: %w", op, err)
-		}
-		caCertPool := x509.NewCertPool()
-		if ok := caCertPool.AppendCertsFromPEM(caCert); !ok {
-			return nil, fmt.Errorf("%s: append certificate: %w", op, err)
-		}
-		t.cl.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: false,
-				RootCAs:            caCertPool,
-			},
-		}
-		t.log.Debug("Certs",
-			zap.String("op", op),
-			zap.String("name", c.GetName()),
-			zap.Int("id", c.GetID()),
-			zap.String("certs path", path))
 	}
 
-	t.cl.Timeout = timeout
+	// Copy of client, so configs can be sent concurrently.
+	cl := *t.cl
+	cl.Transport = tr
+	cl.Timeout = timeout
 
-	if len(t.jar) > 0 {
-		sb := builderPool.Get().(*strings.Builder)
-		sb.Reset()
-		for k, v := range t.jar {
-			if sb.Len() > 0 {
-				sb.WriteByte(';')
-			}
-			sb.WriteString(k)
-			sb.WriteByte('=')
-			sb.WriteString(v)
-		}
-		req.Header.Set("Cookie", sb.String())
-		builderPool.Put(sb)
-	}
+	cl.Jar = t.ses.jar
 
 	if c.HasFlag(config.FlagUseFileCookies) {
+		// Cookies of file replace cookies of jar, cookies of response are still stored.
+		cl.Jar = nil
 		req.Header.Set("Cookie", "")
 		parser.UnparseCookies(c.GetCookie(), func(ck string) {
 			if req.Header.Get("Cookie") != "" {
@@ -169,37 +148,52 @@ func (t *Transport) clientDo(req *http.Request, c *config.HTTPConfig, timeout ti
 		})
 	}
 
-	res, err := t.cl.Do(req)
+	res, err := cl.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("%s: do request: %w", op, err)
 	}
 
-	t.updateJar(res.Header["Set-Cookie"])
+	if cl.Jar == nil {
+		t.ses.jar.SetCookies(res.Request.URL, res.Cookies())
+	}
 
 	return res, nil
 }
 
-// updateJar updates jar by cookies.
-func (t *Transport) updateJar(cookies []string) {
-	const op = "transport.updateJar"
+// httpTransport returns pooled transport for TLS, proxy, keep-alive and unix socket of config.
+// Transports are shared by session, so connections are reused between configs.
+func (t *Transport) httpTransport(c *config.HTTPConfig) (*http.Transport, error) {
+	const op = "transport.httpTransport"
 
-	for _, c := range cookies {
-		if len(c) == 0 {
-			continue
-		}
-
-		semi := strings.IndexByte(c, ';')
-		if semi != -1 {
-			c = c[:semi]
-		}
+	tk, err := newTLSKey(c.GetCerts(), &c.TLSConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if tk.certs != "" || tk.clientCert != "" {
+		t.log.Debug("Certs",
+			zap.String("op", op),
+			zap.String("name", c.GetName()),
+			zap.Int("id", c.GetID()),
+			zap.String("certs path", tk.certs),
+			zap.String("client cert path", tk.clientCert))
+	}
 
-		k, v, found := strings.Cut(c, "=")
-		if !found {
-			continue
-		}
+	pk, err := newProxyKey(t.ses.proxy, &c.ProxyConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
-		t.jar[k] = v
+	key := trKey{tlsKey: tk, proxyKey: pk, noKeepAlive: parser.IsOff(c.KeepAlive)}
+	if sock, _, ok := parser.ParseUnixURL(c.URL); ok {
+		// Socket is local, so proxy isn't used.
+		key.proxyKey = proxyKey{}
+		key.unixSock = sock
+	}
+	tr, err := t.ses.httpTransport(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	return tr, nil
 }
 
 // readBody reads body response body.
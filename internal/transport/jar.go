@@ -0,0 +1,173 @@
+// Package transport jar.go implemented cookie jar of session.
+// Jar is used by HTTP and WebSocket requests, cookies are scoped by RFC 6265.
+// Jar can be loaded from and saved to file, so sessions survive across runs.
+package transport
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Votline/Gurl-cli/internal/parser"
+)
+
+// jarHeader is a first line of jar file.
+const jarHeader = "# Netscape HTTP Cookie File\n# Written by gurl-cli, edit at your own risk.\n\n"
+
+// jarEntry is a cookie in jar.
+type jarEntry struct {
+	parser.JarCookie
+
+	// seq is a order of creation, older cookies are sent first.
+	seq uint64
+}
+
+// Jar is a cookie jar. It implements http.CookieJar.
+type Jar struct {
+	// entries is a cookies by domain, path and name.
+	entries map[string]jarEntry
+
+	// seq is a counter of created cookies.
+	seq uint64
+
+	// mu guards entries and seq.
+	mu sync.Mutex
+}
+
+// NewJar returns empty jar.
+func NewJar() *Jar {
+	return &Jar{entries: map[string]jarEntry{}}
+}
+
+// SetCookies stores cookies of response to url.
+// Expired cookie deletes stored one.
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	now := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, c := range cookies {
+		jc, ok := parser.ParseJarCookie(u, c, now)
+		if !ok {
+			continue
+		}
+		j.set(jc, now)
+	}
+}
+
+// Cookies returns cookies for request to url.
+// Longer paths are first, then older cookies, like RFC 6265 says.
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	now := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var sel []jarEntry
+	for key, e := range j.entries {
+		if e.Expired(now) {
+			delete(j.entries, key)
+			continue
+		}
+		if e.Match(u) {
+			sel = append(sel, e)
+		}
+	}
+	slices.SortFunc(sel, func(a, b jarEntry) int {
+		if d := len(b.Path) - len(a.Path); d != 0 {
+			return d
+		}
+		return cmp.Compare(a.seq, b.seq)
+	})
+
+	cookies := make([]*http.Cookie, len(sel))
+	for i, e := range sel {
+		cookies[i] = &http.Cookie{Name: e.Name, Value: e.Value}
+	}
+	return cookies
+}
+
+// Load adds cookies from jar file.
+// Missing file is not an error, it is created by Save.
+func (j *Jar) Load(path string) error {
+	const op = "transport.Jar.Load"
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	cookies, err := parser.ParseJarFile(data)
+	if err != nil {
+		return fmt.Errorf("%s: %q: %w", op, path, err)
+	}
+
+	now := time.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, jc := range cookies {
+		j.set(jc, now)
+	}
+	return nil
+}
+
+// Save writes not expired cookies to jar file.
+// Session cookies are saved too, so session survives across runs.
+func (j *Jar) Save(path string) error {
+	const op = "transport.Jar.Save"
+
+	now := time.Now()
+	j.mu.Lock()
+	sel := make([]jarEntry, 0, len(j.entries))
+	for _, e := range j.entries {
+		if !e.Expired(now) {
+			sel = append(sel, e)
+		}
+	}
+	j.mu.Unlock()
+
+	slices.SortFunc(sel, func(a, b jarEntry) int {
+		return cmp.Or(cmp.Compare(a.Domain, b.Domain), cmp.Compare(a.Path, b.Path), cmp.Compare(a.seq, b.seq))
+	})
+
+	buf := []byte(jarHeader)
+	for i := range sel {
+		buf = parser.AppendJarCookie(buf, &sel[i].JarCookie)
+	}
+
+	if err := parser.WriteFileAtomic(path, buf, 0o600); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// set stores cookie or deletes it if it is expired.
+// Replaced cookie keeps its order. Caller must hold mu.
+func (j *Jar) set(jc parser.JarCookie, now time.Time) {
+	key := jc.Key()
+	if jc.Expired(now) {
+		delete(j.entries, key)
+		return
+	}
+
+	e := jarEntry{JarCookie: jc}
+	if old, ok := j.entries[key]; ok {
+		e.seq = old.seq
+	} else {
+		j.seq++
+		e.seq = j.seq
+	}
+	j.entries[key] = e
+}
@@ -0,0 +1,208 @@
+package transport
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// jarSet is a 'Set-Cookie' headers of response to url.
+type jarSet struct {
+	url     string
+	cookies []string
+}
+
+// jarQuery is a expected 'Cookie' header of request to url.
+type jarQuery struct {
+	url      string
+	expected string
+}
+
+// setCookies stores 'Set-Cookie' headers in jar like response to url.
+func setCookies(t testing.TB, j *Jar, s jarSet) {
+	u, err := url.Parse(s.url)
+	if err != nil {
+		t.Fatalf("parse url %q: %v", s.url, err)
+	}
+	res := &http.Response{Header: http.Header{"Set-Cookie": s.cookies}}
+	j.SetCookies(u, res.Cookies())
+}
+
+// cookieHeader returns 'Cookie' header of request to url.
+func cookieHeader(t testing.TB, j *Jar, raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse url %q: %v", raw, err)
+	}
+
+	var pairs []string
+	for _, c := range j.Cookies(u) {
+		pairs = append(pairs, c.Name+"="+c.Value)
+	}
+	return strings.Join(pairs, "; ")
+}
+
+func TestJar(t *testing.T) {
+	tests := []struct {
+		sets    []jarSet
+		queries []jarQuery
+	}{
+		{
+			[]jarSet{{"http://api.example.com/", []string{"a=1; Domain=example.com", "h=2"}}},
+			[]jarQuery{
+				{"http://api.example.com/", "a=1; h=2"},
+				{"http://www.example.com/", "a=1"},
+				{"http://sub.api.example.com/", "a=1"},
+				{"http://example.org/", ""},
+			},
+		},
+		{
+			[]jarSet{{"http://api.example.com/", []string{"x=1; Domain=other.com", "y=2; Domain=com"}}},
+			[]jarQuery{{"http://other.com/", ""}, {"http://api.example.com/", ""}},
+		},
+		{
+			[]jarSet{{"http://h.test/a/b", []string{"p=1", "q=2; Path=/admin"}}},
+			[]jarQuery{
+				{"http://h.test/a/c", "p=1"},
+				{"http://h.test/admin/x", "q=2"},
+				{"http://h.test/admin", "q=2"},
+				{"http://h.test/administrator", ""},
+				{"http://h.test/", ""},
+			},
+		},
+		{
+			[]jarSet{{"http://h.test/", []string{"r=1; Path=/", "s=2; Path=/x"}}},
+			[]jarQuery{{"http://h.test/x/y", "s=2; r=1"}},
+		},
+		{
+			[]jarSet{
+				{"http://h.test/", []string{"d=1", "v=1", "m=1"}},
+				{"http://h.test/", []string{"d=; Max-Age=0", "v=2", "m=1; Max-Age=3600"}},
+			},
+			[]jarQuery{{"http://h.test/", "v=2; m=1"}},
+		},
+		{
+			[]jarSet{
+				{"http://h.test/", []string{"e=1", "f=1; Expires=Wed, 01 Jan 3000 00:00:00 GMT"}},
+				{"http://h.test/", []string{"e=1; Expires=Thu, 01 Jan 1970 00:00:01 GMT"}},
+			},
+			[]jarQuery{{"http://h.test/", "f=1"}},
+		},
+		{
+			[]jarSet{{"https://s.test/", []string{"sec=1; Secure", "pub=2"}}},
+			[]jarQuery{
+				{"http://s.test/", "pub=2"},
+				{"ws://s.test/", "pub=2"},
+				{"https://s.test/", "sec=1; pub=2"},
+				{"wss://s.test/", "sec=1; pub=2"},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		j := NewJar()
+		for _, s := range tt.sets {
+			setCookies(t, j, s)
+		}
+		for _, q := range tt.queries {
+			if got := cookieHeader(t, j, q.url); got != q.expected {
+				t.Errorf("[%d] %s: expected %q, but got %q", i, q.url, q.expected, got)
+			}
+		}
+	}
+}
+
+func BenchmarkJar(b *testing.B) {
+	j := NewJar()
+	setCookies(b, j, jarSet{"http://api.example.com/a/b", []string{"a=1; Domain=example.com", "h=2", "p=3; Path=/a"}})
+	u, _ := url.Parse("http://api.example.com/a/c")
+	for b.Loop() {
+		j.Cookies(u)
+	}
+}
+
+func TestJarExpiry(t *testing.T) {
+	j := NewJar()
+	setCookies(t, j, jarSet{"http://h.test/", []string{"t=1; Max-Age=3600", "s=2"}})
+
+	for key, e := range j.entries {
+		if e.Name == "t" {
+			e.Expires = time.Now().Add(-time.Second)
+			j.entries[key] = e
+		}
+	}
+
+	if got := cookieHeader(t, j, "http://h.test/"); got != "s=2" {
+		t.Errorf("expected %q, but got %q", "s=2", got)
+	}
+	if len(j.entries) != 1 {
+		t.Errorf("expected expired cookie to be deleted, but got %d cookies", len(j.entries))
+	}
+}
+
+func TestJarSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	log := zap.NewNop()
+
+	ses, err := NewSession(path, "", log)
+	if err != nil {
+		t.Fatalf("new session: %v", err)
+	}
+	setCookies(t, ses.jar, jarSet{"https://api.example.com/v1/login", []string{
+		"sid=s1; Path=/; Secure; HttpOnly",
+		"pref=dark; Domain=example.com; Path=/; Max-Age=3600",
+		"tmp=1",
+		"old=1; Max-Age=3600",
+	}})
+	for key, e := range ses.jar.entries {
+		if e.Name == "old" {
+			e.Expires = time.Now().Add(-time.Second)
+			ses.jar.entries[key] = e
+		}
+	}
+	if err := ses.Close(); err != nil {
+		t.Fatalf("close session: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read jar: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "# Netscape HTTP Cookie File") {
+		t.Errorf("expected Netscape header, but got %q", data)
+	}
+	if strings.Contains(string(data), "old") {
+		t.Errorf("expected expired cookie not to be saved, but got %q", data)
+	}
+
+	loaded, err := NewSession(path, "", log)
+	if err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	queries := []jarQuery{
+		{"https://api.example.com/v1/users", "tmp=1; sid=s1; pref=dark"},
+		{"http://api.example.com/v1/users", "tmp=1; pref=dark"},
+		{"https://www.example.com/", "pref=dark"},
+		{"https://api.example.com/", "sid=s1; pref=dark"},
+	}
+	for i, q := range queries {
+		if got := cookieHeader(t, loaded.jar, q.url); got != q.expected {
+			t.Errorf("[%d] %s: expected %q, but got %q", i, q.url, q.expected, got)
+		}
+	}
+}
+
+func BenchmarkJarSave(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "cookies.txt")
+	j := NewJar()
+	setCookies(b, j, jarSet{"https://api.example.com/", []string{"sid=s1; Secure", "pref=dark; Domain=example.com; Max-Age=3600"}})
+	for b.Loop() {
+		j.Save(path)
+	}
+}
@@ -0,0 +1,245 @@
+// Package transport proxy.go selects proxy of HTTP, WebSocket and gRPC configs.
+// Proxy is taken from config, then from '--proxy' flag, then from env vars.
+// HTTP requests are proxied by net/http, WebSocket and gRPC are dialed through tunnel.
+package transport
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Votline/Gurl-cli/internal/config"
+	"github.com/Votline/Gurl-cli/internal/parser"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+// proxyKey is a proxy settings of config.
+// It is a part of key of pooled transport.
+type proxyKey struct {
+	// httpProxy and httpsProxy are proxy URLs for 'http' and 'https' requests.
+	// Empty for direct connection.
+	httpProxy  string
+	httpsProxy string
+
+	// noProxy is a comma separated list of hosts connected directly.
+	noProxy string
+}
+
+// bufConn is a connection with bytes read after CONNECT response.
+type bufConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// Read reads buffered bytes first.
+func (c *bufConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// sessionProxy accepts value of '--proxy' flag.
+// Returns proxy of session: flag or 'HTTP_PROXY', 'HTTPS_PROXY', 'ALL_PROXY' and 'NO_PROXY' env vars.
+// Lower case env vars are used too.
+func sessionProxy(flag string) (proxyKey, error) {
+	const op = "transport.sessionProxy"
+
+	env := httpproxy.FromEnvironment()
+	all := cmp.Or(os.Getenv("ALL_PROXY"), os.Getenv("all_proxy"))
+	k := proxyKey{
+		httpProxy:  cmp.Or(env.HTTPProxy, all),
+		httpsProxy: cmp.Or(env.HTTPSProxy, all),
+		noProxy:    env.NoProxy,
+	}
+
+	u, err := parser.ParseProxy([]byte(flag))
+	if err != nil {
+		return proxyKey{}, fmt.Errorf("%s: --proxy: %w", op, err)
+	}
+	if u != nil {
+		k.httpProxy, k.httpsProxy = u.String(), u.String()
+	}
+	return k, nil
+}
+
+// newProxyKey accepts proxy of session and proxy fields of config.
+// 'Proxy: off' disables proxy of session, 'NoProxy' replaces its list.
+func newProxyKey(def proxyKey, pc *config.ProxyConfig) (proxyKey, error) {
+	const op = "transport.newProxyKey"
+
+	k := def
+	switch {
+	case len(pc.Proxy) == 0:
+	case parser.IsOff(pc.Proxy):
+		k.httpProxy, k.httpsProxy = "", ""
+	default:
+		u, err := parser.ParseProxy(pc.Proxy)
+		if err != nil {
+			return proxyKey{}, fmt.Errorf("%s: %w", op, err)
+		}
+		if u != nil {
+			k.httpProxy, k.httpsProxy = u.String(), u.String()
+		}
+	}
+
+	if len(pc.NoProxy) != 0 {
+		k.noProxy = strings.TrimSpace(string(pc.NoProxy))
+	}
+	return k, nil
+}
+
+// enabled reports whether any proxy is set.
+func (k *proxyKey) enabled() bool {
+	return k.httpProxy != "" || k.httpsProxy != ""
+}
+
+// proxyFunc returns proxy URL for request URL, nil for direct connection.
+// Loopback hosts and hosts of 'NoProxy' are connected directly.
+func (k *proxyKey) proxyFunc() func(*url.URL) (*url.URL, error) {
+	if !k.enabled() {
+		return nil
+	}
+	return (&httpproxy.Config{
+		HTTPProxy:  k.httpProxy,
+		HTTPSProxy: k.httpsProxy,
+		NoProxy:    k.noProxy,
+	}).ProxyFunc()
+}
+
+// proxyURL returns proxy URL for 'host:port', nil for direct connection.
+// If secure is true, proxy of 'https' requests is used.
+func (k *proxyKey) proxyURL(addr string, secure bool) (*url.URL, error) {
+	fn := k.proxyFunc()
+	if fn == nil || addr == "" {
+		return nil, nil
+	}
+
+	scheme := "http"
+	if secure {
+		scheme = "https"
+	}
+	return fn(&url.URL{Scheme: scheme, Host: addr})
+}
+
+// dial connects to 'host:port' through proxy or directly.
+// HTTP and HTTPS proxies are tunneled by CONNECT, SOCKS5 resolves host by itself.
+func (k *proxyKey) dial(ctx context.Context, addr string, secure bool) (net.Conn, error) {
+	const op = "transport.proxyKey.dial"
+
+	d := &net.Dialer{Timeout: dialTimeout, KeepAlive: keepAlivePeriod}
+
+	pu, err := k.proxyURL(addr, secure)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if pu == nil {
+		return d.DialContext(ctx, "tcp", addr)
+	}
+
+	switch pu.Scheme {
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if pu.User != nil {
+			pass, _ := pu.User.Password()
+			auth = &proxy.Auth{User: pu.User.Username(), Password: pass}
+		}
+		sd, err := proxy.SOCKS5("tcp", proxyAddr(pu), auth, d)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		conn, err := sd.(proxy.ContextDialer).DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: socks5 %s: %w", op, pu.Redacted(), err)
+		}
+		return conn, nil
+	case "http", "https":
+		conn, err := dialConnect(ctx, d, pu, addr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return conn, nil
+	}
+	return nil, fmt.Errorf("%s: unsupported proxy scheme %q", op, pu.Scheme)
+}
+
+// dialConnect opens tunnel to 'host:port' by CONNECT request to HTTP or HTTPS proxy.
+// Credentials of proxy URL are sent as basic 'Proxy-Authorization'.
+func dialConnect(ctx context.Context, d *net.Dialer, pu *url.URL, addr string) (net.Conn, error) {
+	const op = "transport.dialConnect"
+
+	conn, err := d.DialContext(ctx, "tcp", proxyAddr(pu))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(dl)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	if pu.Scheme == "https" {
+		tc := tls.Client(conn, &tls.Config{ServerName: pu.Hostname()})
+		if err := tc.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s: handshake with %s: %w", op, pu.Redacted(), err)
+		}
+		conn = tc
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if pu.User != nil {
+		pass, _ := pu.User.Password()
+		cred := base64.StdEncoding.EncodeToString([]byte(pu.User.Username() + ":" + pass))
+		req.Header.Set("Proxy-Authorization", "Basic "+cred)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s: write CONNECT: %w", op, err)
+	}
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s: read CONNECT response: %w", op, err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("%s: %s: CONNECT %s: %s", op, pu.Redacted(), addr, res.Status)
+	}
+
+	if br.Buffered() > 0 {
+		return &bufConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// proxyAddr returns 'host:port' of proxy.
+// Default ports are 80 for HTTP, 443 for HTTPS and 1080 for SOCKS5.
+func proxyAddr(pu *url.URL) string {
+	if pu.Port() != "" {
+		return pu.Host
+	}
+
+	port := "80"
+	switch pu.Scheme {
+	case "https":
+		port = "443"
+	case "socks5", "socks5h":
+		port = "1080"
+	}
+	return net.JoinHostPort(pu.Hostname(), port)
+}
@@ -4,19 +4,11 @@ package transport
 import (
 	"crypto/tls"
 	"net/http"
-	"strings"
-	"sync"
+	"time"
 
 	"go.uber.org/zap"
 )
 
-// builderPool is a sync.Pool for strings.Builder
-var builderPool = sync.Pool{
-	New: func() any {
-		return new(strings.Builder)
-	},
-}
-
 // Status is a struct for response status.
 type Status struct {
 	// Code is a response code.
@@ -45,12 +37,83 @@ type Result struct {
 
 	// Cookie is a raw cookie.
 	Cookie []byte
+
+	// Proto is a response protocol. Like 'HTTP/1.1'.
+	Proto string
+
+	// Header is a response headers.
+	// For gRPC it is a header metadata.
+	Header http.Header
+
+	// Trailer is a response trailers.
+	// For gRPC it is a trailer metadata.
+	Trailer http.Header
+
+	// Size is a size of raw response.
+	Size int
+
+	// Duration is a time between sending request and reading response.
+	Duration time.Duration
+
+	// Attempts is a count of sends by 'Retry' policy.
+	Attempts int
+
+	// Row is a number of 'DataFile' row or 'ForEach' element from 1.
+	// Zero for config without data file or for each.
+	Row int
+
+	// Item is a label of 'ForEach' element, like 'ItemID=42'.
+	Item string
+
+	// Imported is a results of imported file.
+	// Only for import config.
+	Imported *Results
+
+	// Skipped is true if config is skipped by 'If' condition.
+	Skipped bool
+
+	// TLS is a details of TLS handshake, nil for plain connection.
+	TLS *TLSInfo
+}
+
+// TLSInfo is a details of TLS handshake.
+type TLSInfo struct {
+	// Version is a negotiated TLS version. Like 'TLS 1.3'.
+	Version string
+
+	// Cipher is a negotiated cipher suite.
+	Cipher string
+
+	// ServerName is a name sent by SNI.
+	ServerName string
+
+	// Peers is a certificates of server, leaf first.
+	Peers []PeerCert
+}
+
+// PeerCert is a certificate of server.
+type PeerCert struct {
+	// Subject and Issuer are distinguished names of certificate.
+	Subject string
+	Issuer  string
+
+	// NotAfter is a time of expiry.
+	NotAfter time.Time
+}
+
+// Results is a results of one config file.
+type Results struct {
+	// Hub is a list of results by config id.
+	Hub []*Result
+
+	// Names maps config names to ids.
+	Names map[string]int
 }
 
 // Transport is a struct for transport package.
 type Transport struct {
-	// jar is a map for cookies.
-	jar map[string]string
+	// ses is a session of run with pool of http transports and cookie jar.
+	ses *Session
 
 	// cl is a http.Client.
 	cl *http.Client
@@ -59,9 +122,9 @@ type Transport struct {
 	log *zap.Logger
 }
 
-// NewTransport accepts callback for result and zap.Logger.
+// NewTransport accepts callback for result, session of run and zap.Logger.
 // It returns new Transport.
-func NewTransport(putRes func(*Result), log *zap.Logger) *Transport {
+func NewTransport(putRes func(*Result), ses *Session, log *zap.Logger) *Transport {
 	for i := 0; i < 10; i++ {
 		putRes(&Result{})
 	}
@@ -72,5 +135,5 @@ func NewTransport(putRes func(*Result), log *zap.Logger) *Transport {
 		},
 	}
 
-	return &Transport{jar: map[string]string{}, cl: client, log: log}
+	return &Transport{ses: ses, cl: client, log: log}
 }
@@ -0,0 +1,166 @@
+// Package transport session.go keeps state shared by all files of one run.
+// Transports are pooled, so connections are reused by configs and imported files.
+// Cookie jar is shared too, so cookies of imported file are sent by importing one.
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Settings of pooled http transports.
+const (
+	// dialTimeout is a timeout of TCP connect.
+	dialTimeout = 30 * time.Second
+
+	// keepAlivePeriod is a period of TCP keep-alive probes.
+	keepAlivePeriod = 30 * time.Second
+
+	// maxIdleConns is a limit of idle connections of transport.
+	// It is used per host too, so parallel configs don't close connections.
+	maxIdleConns = 100
+
+	// idleConnTimeout is a time after which idle connection is closed.
+	idleConnTimeout = 90 * time.Second
+
+	// tlsHandshakeTimeout is a timeout of TLS handshake.
+	tlsHandshakeTimeout = 10 * time.Second
+)
+
+// trKey is a key of http transport in pool.
+// Configs with equal keys share connections.
+type trKey struct {
+	// tlsKey is a TLS settings of config.
+	tlsKey
+
+	// proxyKey is a proxy settings of config.
+	proxyKey
+
+	// noKeepAlive is true for 'KeepAlive: off'.
+	noKeepAlive bool
+
+	// unixSock is a path to unix socket, empty for TCP.
+	unixSock string
+}
+
+// Session is a state of transport shared by all files of one run.
+type Session struct {
+	// trs is a pool of http transports.
+	trs map[trKey]*http.Transport
+
+	// mu guards trs.
+	mu sync.Mutex
+
+	// jar is a cookie jar of HTTP and WebSocket requests.
+	jar *Jar
+
+	// jarPath is a path to jar file, empty if jar isn't saved.
+	jarPath string
+
+	// proxy is a proxy of '--proxy' flag or env vars.
+	// Configs override it by 'Proxy' and 'NoProxy' fields.
+	proxy proxyKey
+
+	// log is a zap.Logger.
+	log *zap.Logger
+}
+
+// NewSession accepts path to cookie jar file, proxy URL and zap.Logger.
+// It returns new Session with empty pool and jar loaded from file.
+// Jar isn't loaded and saved if path is empty.
+// Proxy env vars are used if proxy URL is empty.
+func NewSession(jarPath, proxyURL string, log *zap.Logger) (*Session, error) {
+	const op = "transport.NewSession"
+
+	pk, err := sessionProxy(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s := &Session{trs: map[trKey]*http.Transport{}, jar: NewJar(), jarPath: jarPath, proxy: pk, log: log}
+	if jarPath != "" {
+		if err := s.jar.Load(jarPath); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return s, nil
+}
+
+// Close closes idle connections of all pooled transports and saves jar.
+// Session can be used after Close, connections are opened again.
+func (s *Session) Close() error {
+	const op = "transport.Session.Close"
+
+	s.mu.Lock()
+	for _, tr := range s.trs {
+		tr.CloseIdleConnections()
+	}
+	s.mu.Unlock()
+
+	if s.jarPath != "" {
+		if err := s.jar.Save(s.jarPath); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return nil
+}
+
+// httpTransport returns pooled transport for key.
+// New transport is created on first use of key.
+func (s *Session) httpTransport(key trKey) (*http.Transport, error) {
+	const op = "transport.Session.httpTransport"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tr, ok := s.trs[key]; ok {
+		return tr, nil
+	}
+
+	tlsCfg, err := key.config()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	d := &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: keepAlivePeriod,
+	}
+	tr := &http.Transport{
+		DialContext:         d.DialContext,
+		TLSClientConfig:     tlsCfg,
+		TLSHandshakeTimeout: tlsHandshakeTimeout,
+		MaxIdleConns:        maxIdleConns,
+		MaxIdleConnsPerHost: maxIdleConns,
+		IdleConnTimeout:     idleConnTimeout,
+		DisableKeepAlives:   key.noKeepAlive,
+	}
+	if key.unixSock != "" {
+		// Host of request is 'localhost', every connection goes to socket.
+		tr.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return d.DialContext(ctx, "unix", key.unixSock)
+		}
+	} else if fn := key.proxyFunc(); fn != nil {
+		tr.Proxy = func(req *http.Request) (*url.URL, error) {
+			return fn(req.URL)
+		}
+	}
+
+	s.log.Debug("New http transport",
+		zap.String("op", op),
+		zap.String("certs path", key.certs),
+		zap.String("client cert path", key.clientCert),
+		zap.Bool("keep-alive", !key.noKeepAlive),
+		zap.Bool("proxy", key.enabled()),
+		zap.String("unix socket", key.unixSock))
+
+	s.trs[key] = tr
+	return tr, nil
+}
@@ -0,0 +1,113 @@
+package transport
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Votline/Gurl-cli/internal/config"
+
+	"go.uber.org/zap"
+)
+
+// newConnServer starts TLS server which counts new connections.
+// Returns server, counter and path to its CA certificate.
+func newConnServer(t testing.TB) (*httptest.Server, *atomic.Int64, string) {
+	var conns atomic.Int64
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	s.Config.ConnState = func(_ net.Conn, st http.ConnState) {
+		if st == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	s.StartTLS()
+	t.Cleanup(s.Close)
+
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw})
+	if err := os.WriteFile(ca, data, 0o644); err != nil {
+		t.Fatalf("write certificate: %v", err)
+	}
+	return s, &conns, ca
+}
+
+// testConfig is a HTTP config for pool tests.
+type testConfig struct {
+	certs     string
+	keepAlive string
+}
+
+// httpConfig returns HTTP config with URL, certs and keep-alive.
+func (tc testConfig) httpConfig(url string) *config.HTTPConfig {
+	c := &config.HTTPConfig{URL: []byte(url), Method: []byte("GET")}
+	c.Timeout = []byte("5s")
+	if tc.certs != "" {
+		c.Certs = []byte(tc.certs)
+	}
+	if tc.keepAlive != "" {
+		c.KeepAlive = []byte(tc.keepAlive)
+	}
+	return c
+}
+
+func TestSessionHTTPTransport(t *testing.T) {
+	const ca = "{CA}"
+
+	tests := []struct {
+		cfgs  []testConfig
+		trs   int
+		conns int64
+	}{
+		{[]testConfig{{}, {}, {}}, 1, 1},
+		{[]testConfig{{certs: ca}, {certs: ca}}, 1, 1},
+		{[]testConfig{{}, {certs: ca}, {}, {certs: ca}}, 2, 2},
+		{[]testConfig{{}, {keepAlive: "off"}, {keepAlive: "off"}, {}}, 2, 3},
+		{[]testConfig{{keepAlive: "on"}, {}}, 1, 1},
+	}
+
+	log := zap.NewNop()
+	for i, tt := range tests {
+		s, conns, caPath := newConnServer(t)
+		ses, err := NewSession("", "", log)
+		if err != nil {
+			t.Fatalf("new session: %v", err)
+		}
+		trnsp := NewTransport(func(*Result) {}, ses, log)
+
+		for j, tc := range tt.cfgs {
+			if tc.certs == ca {
+				tc.certs = caPath
+			}
+			if err := trnsp.DoHTTP(tc.httpConfig(s.URL), new(Result), true); err != nil {
+				t.Errorf("[%d][%d]: unexpected error: %v", i, j, err)
+			}
+		}
+
+		if len(ses.trs) != tt.trs {
+			t.Errorf("[%d]: expected %d transports, but got %d", i, tt.trs, len(ses.trs))
+		}
+		if n := conns.Load(); n != tt.conns {
+			t.Errorf("[%d]: expected %d connections, but got %d", i, tt.conns, n)
+		}
+		ses.Close()
+	}
+}
+
+func BenchmarkSessionHTTPTransport(b *testing.B) {
+	ses, err := NewSession("", "", zap.NewNop())
+	if err != nil {
+		b.Fatalf("new session: %v", err)
+	}
+	key := trKey{noKeepAlive: true}
+	for b.Loop() {
+		ses.httpTransport(key)
+	}
+}
@@ -0,0 +1,303 @@
+// Package transport tls.go builds TLS settings of HTTP, WebSocket and gRPC configs.
+// Here are CA certificate, client certificate for mTLS and handshake details.
+package transport
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"strings"
+
+	"github.com/Votline/Gurl-cli/internal/config"
+	"github.com/Votline/Gurl-cli/internal/parser"
+)
+
+// Object identifiers of encrypted PKCS #8 key, RFC 8018.
+var (
+	oidPBES2      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// tlsKey is a TLS settings of config.
+// It is a part of key of pooled transport.
+type tlsKey struct {
+	// certs is a path to CA certificate, empty if verification is skipped.
+	certs string
+
+	// clientCert and clientKey are paths to PEM files of client certificate.
+	clientCert string
+	clientKey  string
+
+	// keyPass is a passphrase of encrypted client key.
+	keyPass string
+
+	// serverName is a name for SNI and verification instead of host.
+	serverName string
+
+	// minVersion is a minimal TLS version, 0 for default.
+	minVersion uint16
+}
+
+// pkcs8Encrypted is an 'ENCRYPTED PRIVATE KEY' block.
+type pkcs8Encrypted struct {
+	Algo pkix.AlgorithmIdentifier
+	Data []byte
+}
+
+// pbes2Params is a parameters of PBES2 scheme.
+type pbes2Params struct {
+	KDF    pkix.AlgorithmIdentifier
+	Cipher pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params is a parameters of PBKDF2 function.
+type pbkdf2Params struct {
+	Salt   []byte
+	Iter   int
+	KeyLen int                      `asn1:"optional"`
+	PRF    pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// newTLSKey accepts certs field and TLS fields of config.
+// Returns settings or error if fields are invalid.
+func newTLSKey(certs []byte, tc *config.TLSConfig) (tlsKey, error) {
+	const op = "transport.newTLSKey"
+
+	var k tlsKey
+	if certs != nil && !parser.EqualFold(certs, "ignore") {
+		k.certs = strings.TrimSpace(string(certs))
+	}
+	k.clientCert = strings.TrimSpace(string(tc.ClientCert))
+	k.clientKey = strings.TrimSpace(string(tc.ClientKey))
+	k.keyPass = string(tc.ClientKeyPass)
+	k.serverName = strings.TrimSpace(string(tc.ServerName))
+
+	if (k.clientCert == "") != (k.clientKey == "") {
+		return tlsKey{}, fmt.Errorf("%s: ClientCert and ClientKey must be set together", op)
+	}
+
+	var err error
+	if k.minVersion, err = parser.ParseTLSVersion(tc.TLSMinVersion); err != nil {
+		return tlsKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return k, nil
+}
+
+// config returns tls.Config for settings.
+// Server certificate isn't verified without CA certificate.
+func (k *tlsKey) config() (*tls.Config, error) {
+	const op = "transport.tlsKey.config"
+
+	cfg := &tls.Config{
+		InsecureSkipVerify: true,
+		ServerName:         k.serverName,
+		MinVersion:         k.minVersion,
+	}
+
+	if k.certs != "" {
+		caCert, err := os.ReadFile(k.certs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: read certificate: %w", op, err)
+		}
+		caCertPool := x509.NewCertPool()
+		if ok := caCertPool.AppendCertsFromPEM(caCert); !ok {
+			return nil, fmt.Errorf("%s: append certificate %q: invalid PEM", op, k.certs)
+		}
+		cfg.InsecureSkipVerify = false
+		cfg.RootCAs = caCertPool
+	}
+
+	if k.clientCert != "" {
+		cert, err := loadClientCert(k.clientCert, k.clientKey, k.keyPass)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// loadClientCert reads PEM files of client certificate and key.
+// Key is decrypted by passphrase if it is set.
+func loadClientCert(certPath, keyPath, pass string) (tls.Certificate, error) {
+	const op = "transport.loadClientCert"
+
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("%s: read client certificate: %w", op, err)
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("%s: read client key: %w", op, err)
+	}
+
+	if pass != "" {
+		if keyPEM, err = decryptKey(keyPEM, []byte(pass)); err != nil {
+			return tls.Certificate{}, fmt.Errorf("%s: %q: %w", op, keyPath, err)
+		}
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("%s: %q, %q: %w", op, certPath, keyPath, err)
+	}
+	return cert, nil
+}
+
+// decryptKey returns PEM of decrypted private key.
+// Key is PKCS #8 'ENCRYPTED PRIVATE KEY' or legacy encrypted PEM.
+// Not encrypted key is returned as is.
+func decryptKey(data, pass []byte) ([]byte, error) {
+	const op = "transport.decryptKey"
+
+	for rest := data; len(rest) != 0; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+
+		switch {
+		case block.Type == "ENCRYPTED PRIVATE KEY":
+			der, err := decryptPKCS8(block.Bytes, pass)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+		case strings.HasSuffix(block.Type, "PRIVATE KEY") && x509.IsEncryptedPEMBlock(block):
+			der, err := x509.DecryptPEMBlock(block, pass)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			return pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: der}), nil
+		}
+	}
+
+	return data, nil
+}
+
+// decryptPKCS8 decrypts PKCS #8 key by PBES2 scheme with PBKDF2 and AES-CBC.
+// It is a default of 'openssl genpkey -aes256' and 'openssl pkcs8 -topk8'.
+func decryptPKCS8(der, pass []byte) ([]byte, error) {
+	const op = "transport.decryptPKCS8"
+
+	var enc pkcs8Encrypted
+	if _, err := asn1.Unmarshal(der, &enc); err != nil {
+		return nil, fmt.Errorf("%s: parse key: %w", op, err)
+	}
+	if !enc.Algo.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("%s: unsupported encryption %s, expected PBES2", op, enc.Algo.Algorithm)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(enc.Algo.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("%s: parse PBES2 parameters: %w", op, err)
+	}
+	if !params.KDF.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("%s: unsupported key derivation %s, expected PBKDF2", op, params.KDF.Algorithm)
+	}
+
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KDF.Parameters.FullBytes, &kdf); err != nil {
+		return nil, fmt.Errorf("%s: parse PBKDF2 parameters: %w", op, err)
+	}
+
+	var prf func() hash.Hash
+	switch {
+	case len(kdf.PRF.Algorithm) == 0, kdf.PRF.Algorithm.Equal(oidHMACSHA1):
+		prf = sha1.New
+	case kdf.PRF.Algorithm.Equal(oidHMACSHA256):
+		prf = sha256.New
+	case kdf.PRF.Algorithm.Equal(oidHMACSHA512):
+		prf = sha512.New
+	default:
+		return nil, fmt.Errorf("%s: unsupported PRF %s", op, kdf.PRF.Algorithm)
+	}
+
+	var keyLen int
+	switch {
+	case params.Cipher.Algorithm.Equal(oidAES128CBC):
+		keyLen = 16
+	case params.Cipher.Algorithm.Equal(oidAES192CBC):
+		keyLen = 24
+	case params.Cipher.Algorithm.Equal(oidAES256CBC):
+		keyLen = 32
+	default:
+		return nil, fmt.Errorf("%s: unsupported cipher %s, expected AES-CBC", op, params.Cipher.Algorithm)
+	}
+
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.Cipher.Parameters.FullBytes, &iv); err != nil || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("%s: invalid IV", op)
+	}
+
+	key, err := pbkdf2.Key(prf, string(pass), kdf.Salt, kdf.Iter, keyLen)
+	if err != nil {
+		return nil, fmt.Errorf("%s: derive key: %w", op, err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	data := enc.Data
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("%s: invalid encrypted data length %d", op, len(data))
+	}
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+
+	pad := int(out[len(out)-1])
+	if pad == 0 || pad > aes.BlockSize {
+		return nil, fmt.Errorf("%s: %w", op, errWrongPass)
+	}
+	for _, b := range out[len(out)-pad:] {
+		if int(b) != pad {
+			return nil, fmt.Errorf("%s: %w", op, errWrongPass)
+		}
+	}
+	return out[:len(out)-pad], nil
+}
+
+// errWrongPass is an error of decryption with wrong passphrase.
+var errWrongPass = errors.New("wrong passphrase or corrupted key")
+
+// newTLSInfo returns details of TLS handshake, nil for plain connection.
+func newTLSInfo(cs *tls.ConnectionState) *TLSInfo {
+	if cs == nil || !cs.HandshakeComplete {
+		return nil
+	}
+
+	info := &TLSInfo{
+		Version:    tls.VersionName(cs.Version),
+		Cipher:     tls.CipherSuiteName(cs.CipherSuite),
+		ServerName: cs.ServerName,
+		Peers:      make([]PeerCert, 0, len(cs.PeerCertificates)),
+	}
+	for _, c := range cs.PeerCertificates {
+		info.Peers = append(info.Peers, PeerCert{
+			Subject:  c.Subject.String(),
+			Issuer:   c.Issuer.String(),
+			NotAfter: c.NotAfter,
+		})
+	}
+	return info
+}
@@ -0,0 +1,172 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Votline/Gurl-cli/internal/config"
+)
+
+// readTestdata returns content of file from testdata.
+func readTestdata(t testing.TB, name string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	return data
+}
+
+func TestDecryptKey(t *testing.T) {
+	tests := []struct {
+		file    string
+		pass    string
+		wrong   bool
+		legacy  bool
+		plainIn bool
+	}{
+		{"key.pem", "secret", false, false, true},
+		{"key_aes256_sha256.pem", "secret", false, false, false},
+		{"key_aes128_sha1.pem", "secret", false, false, false},
+		{"key_legacy.pem", "secret", false, true, false},
+		{"key_aes256_sha256.pem", "wrong", true, false, false},
+		{"key_aes128_sha1.pem", "wrong", true, false, false},
+		{"key_legacy.pem", "wrong", true, true, false},
+	}
+
+	cert := readTestdata(t, "cert.pem")
+	plain := readTestdata(t, "key.pem")
+	for i, tt := range tests {
+		in := readTestdata(t, tt.file)
+		out, err := decryptKey(in, []byte(tt.pass))
+		if tt.wrong {
+			if err == nil {
+				t.Errorf("[%d]: expected error, but got nil", i)
+			} else if !tt.legacy && !errors.Is(err, errWrongPass) {
+				t.Errorf("[%d]: expected %v, but got %v", i, errWrongPass, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d]: unexpected error: %v", i, err)
+			continue
+		}
+
+		if tt.plainIn && !bytes.Equal(out, in) {
+			t.Errorf("[%d]: expected key as is, but got %q", i, out)
+		}
+		if !tt.legacy && !bytes.Equal(out, plain) {
+			t.Errorf("[%d]: expected %q, but got %q", i, plain, out)
+		}
+		if _, err := tls.X509KeyPair(cert, out); err != nil {
+			t.Errorf("[%d]: key doesn't match certificate: %v", i, err)
+		}
+	}
+}
+
+func BenchmarkDecryptKey(b *testing.B) {
+	key := readTestdata(b, "key_aes128_sha1.pem")
+	pass := []byte("secret")
+	for b.Loop() {
+		decryptKey(key, pass)
+	}
+}
+
+func TestLoadClientCert(t *testing.T) {
+	tests := []struct {
+		key  string
+		pass string
+		err  bool
+	}{
+		{"key.pem", "", false},
+		{"key_aes256_sha256.pem", "secret", false},
+		{"key_legacy.pem", "secret", false},
+		{"key_aes256_sha256.pem", "", true},
+		{"key_aes256_sha256.pem", "wrong", true},
+		{"missing.pem", "", true},
+	}
+
+	for i, tt := range tests {
+		_, err := loadClientCert(filepath.Join("testdata", "cert.pem"), filepath.Join("testdata", tt.key), tt.pass)
+		if tt.err != (err != nil) {
+			t.Errorf("[%d]: expected error %v, but got %v", i, tt.err, err)
+		}
+	}
+}
+
+func BenchmarkLoadClientCert(b *testing.B) {
+	cert, key := filepath.Join("testdata", "cert.pem"), filepath.Join("testdata", "key.pem")
+	for b.Loop() {
+		loadClientCert(cert, key, "")
+	}
+}
+
+func TestNewTLSKey(t *testing.T) {
+	tests := []struct {
+		certs    string
+		tc       config.TLSConfig
+		expected tlsKey
+		err      bool
+	}{
+		{"", config.TLSConfig{}, tlsKey{}, false},
+		{"ignore", config.TLSConfig{}, tlsKey{}, false},
+		{" ca.pem ", config.TLSConfig{}, tlsKey{certs: "ca.pem"}, false},
+		{
+			"",
+			config.TLSConfig{
+				ClientCert:    []byte("cert.pem"),
+				ClientKey:     []byte(" key.pem"),
+				ClientKeyPass: []byte("secret"),
+				ServerName:    []byte("api.local"),
+				TLSMinVersion: []byte("TLS1.3"),
+			},
+			tlsKey{
+				clientCert: "cert.pem", clientKey: "key.pem", keyPass: "secret",
+				serverName: "api.local", minVersion: tls.VersionTLS13,
+			},
+			false,
+		},
+		{"", config.TLSConfig{ClientCert: []byte("cert.pem")}, tlsKey{}, true},
+		{"", config.TLSConfig{ClientKey: []byte("key.pem")}, tlsKey{}, true},
+		{"", config.TLSConfig{TLSMinVersion: []byte("1.4")}, tlsKey{}, true},
+		{"", config.TLSConfig{TLSMinVersion: []byte("ssl3")}, tlsKey{}, true},
+	}
+
+	for i, tt := range tests {
+		var certs []byte
+		if tt.certs != "" {
+			certs = []byte(tt.certs)
+		}
+
+		k, err := newTLSKey(certs, &tt.tc)
+		if tt.err {
+			if err == nil {
+				t.Errorf("[%d]: expected error, but got nil", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d]: unexpected error: %v", i, err)
+			continue
+		}
+		if k != tt.expected {
+			t.Errorf("[%d]: expected %+v, but got %+v", i, tt.expected, k)
+		}
+	}
+}
+
+func BenchmarkNewTLSKey(b *testing.B) {
+	tc := &config.TLSConfig{
+		ClientCert:    []byte("cert.pem"),
+		ClientKey:     []byte("key.pem"),
+		TLSMinVersion: []byte("1.2"),
+	}
+	for b.Loop() {
+		newTLSKey(nil, tc)
+	}
+}
@@ -4,10 +4,11 @@ package transport
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"strings"
@@ -29,27 +30,42 @@ import (
 func (t *Transport) doWS(c *config.HTTPConfig, resObj *Result, wsID int, dp bool) error {
 	const op = "transport.doWS"
 
-	dialer := websocket.DefaultDialer
+	// Copy of default dialer, so configs can be dialed concurrently.
+	dialer := *websocket.DefaultDialer
+	dialer.Jar = t.ses.jar
 
-	certsPath := c.GetCerts()
-
-	if certsPath == nil || parser.EqualFold(certsPath, "ignore") {
-		dialer.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	tk, err := newTLSKey(c.GetCerts(), &c.TLSConfig)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if dialer.TLSClientConfig, err = tk.config(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if dialer.TLSClientConfig.InsecureSkipVerify {
 		t.log.Warn("InsecureSkipVerify is true",
 			zap.String("op", op),
 			zap.String("url", unsafe.String(unsafe.SliceData(c.URL), len(c.URL))))
-	} else {
-		caCert, err := os.ReadFile(unsafe.String(unsafe.SliceData(certsPath), len(certsPath)))
-		if err != nil {
-			return fmt.Errorf("%s: read certificate: %w", op, err)
-		}
-		caCertPool := x509.NewCertPool()
-		if ok := caCertPool.AppendCertsFromPEM(caCert); !ok {
-			return fmt.Errorf("%s: append certificate: %w", op, err)
+	}
+
+	// Proxy of session replaces proxy env vars of default dialer.
+	pk, err := newProxyKey(t.ses.proxy, &c.ProxyConfig)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	dialer.Proxy = nil
+
+	url := unsafe.String(unsafe.SliceData(c.URL), len(c.URL))
+	if sock, unixURL, ok := parser.ParseUnixURL(c.URL); ok {
+		// Socket is local, so proxy isn't used.
+		url = unixURL
+		dialer.NetDialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			d := net.Dialer{Timeout: dialTimeout}
+			return d.DialContext(ctx, "unix", sock)
 		}
-		dialer.TLSClientConfig = &tls.Config{
-			InsecureSkipVerify: false,
-			RootCAs:            caCertPool,
+	} else if pk.enabled() {
+		secure := bytes.HasPrefix(bytes.TrimSpace(c.URL), []byte("wss://"))
+		dialer.NetDialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+			return pk.dial(ctx, addr, secure)
 		}
 	}
 
@@ -61,7 +77,8 @@ func (t *Transport) doWS(c *config.HTTPConfig, resObj *Result, wsID int, dp bool
 		h.Set(key, val)
 	})
 
-	conn, resp, err := dialer.Dial(unsafe.String(unsafe.SliceData(c.URL), len(c.URL)), h)
+	start := time.Now()
+	conn, resp, err := dialer.Dial(url, h)
 	if err != nil {
 		if resp != nil {
 			resObj.Info = Status{
@@ -69,11 +86,21 @@ func (t *Transport) doWS(c *config.HTTPConfig, resObj *Result, wsID int, dp bool
 				Message:    resp.Status,
 				ConfigType: "http",
 			}
+			resObj.Header = resp.Header
+			resObj.Proto = resp.Proto
+			resObj.Duration = time.Since(start)
 		}
 		return fmt.Errorf("%s: dial: %w", op, err)
 	}
 	defer conn.Close()
 
+	resObj.Header = resp.Header
+	resObj.Proto = resp.Proto
+	if tc, ok := conn.UnderlyingConn().(*tls.Conn); ok {
+		cs := tc.ConnectionState()
+		resObj.TLS = newTLSInfo(&cs)
+	}
+
 	if len(c.Body) > 0 {
 		t.log.Debug("Sending body",
 			zap.String("op", op),
@@ -90,8 +117,8 @@ func (t *Transport) doWS(c *config.HTTPConfig, resObj *Result, wsID int, dp bool
 	}
 
 	dur := parser.ParseWait(c.Timeout)
-	if dur != 0 {
-		conn.SetReadDeadline(time.Now().Add(time.Second * time.Duration(dur)))
+	if dur > 0 {
+		conn.SetReadDeadline(time.Now().Add(dur))
 	} else {
 		t.log.Warn("ReadWSWait is empty. Using default timeout of 2 seconds",
 			zap.String("op", op),
@@ -107,6 +134,8 @@ func (t *Transport) doWS(c *config.HTTPConfig, resObj *Result, wsID int, dp bool
 	}
 
 	resObj.Raw = msg
+	resObj.Size = len(msg)
+	resObj.Duration = time.Since(start)
 	resObj.IsJSON = false
 	resObj.Cookie = nil
 	resObj.Info = Status{